@@ -19,7 +19,10 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Arama0517/MCST/internal/configs"
 	"github.com/Arama0517/MCST/internal/download"
@@ -29,6 +32,15 @@ import (
 )
 
 func main() {
+	// 第一次 Ctrl+C 取消下载, 取消后恢复默认行为, 再按一次会直接退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	var backend string
 	cmd := &cobra.Command{
 		Use:                   "download [url]",
 		Args:                  cobra.ExactArgs(1),
@@ -37,8 +49,10 @@ func main() {
 			log.SetHandler(cli.Default)
 			return configs.InitData()
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
-			path, err := download.NewDownloader(args[0]).Download()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			downloader := download.NewDownloader(args[0])
+			downloader.Backend = backend
+			path, err := downloader.Download(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -46,8 +60,8 @@ func main() {
 			return nil
 		},
 	}
-	if err := cmd.Execute(); err != nil {
+	cmd.Flags().StringVar(&backend, "backend", "", "download backend")
+	if err := cmd.ExecuteContext(ctx); err != nil {
 		log.WithError(err).Fatal("下载失败")
-		os.Exit(1)
 	}
 }
@@ -0,0 +1,185 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+const (
+	FabricMetaURL = "https://meta.fabricmc.net/v2"
+	QuiltMetaURL  = "https://meta.quiltmc.org/v3"
+)
+
+func init() {
+	Register(NewFabricProvider(FabricMetaURL))
+	Register(NewQuiltProvider(QuiltMetaURL))
+}
+
+// NewFabricProvider 返回使用 metaURL 的 Fabric 来源, 下载的是可以直接启动的服务端启动器
+func NewFabricProvider(metaURL string) Provider {
+	return loaderProvider{name: "fabric", metaURL: metaURL}
+}
+
+// NewQuiltProvider 返回使用 metaURL 的 Quilt 来源; Quilt 没有现成的服务端, 下载的是安装器, 创建服务器时运行
+func NewQuiltProvider(metaURL string) Provider {
+	return loaderProvider{name: "quilt", metaURL: metaURL, installer: true}
+}
+
+// loaderProvider Fabric 和 Quilt 的 meta API 结构相同
+//
+// 构建版本为加载器版本, 可以使用 "<加载器版本>:<安装器版本>" 同时指定安装器版本; 不指定时使用最新的稳定版
+type loaderProvider struct {
+	name      string
+	metaURL   string
+	installer bool
+}
+
+// LoaderExtras 保存在核心 ExtrasData 中的数据, 使用 [configs.Core.Extras] 读取
+type LoaderExtras struct {
+	Loader           string `yaml:"loader" json:"loader"`
+	MinecraftVersion string `yaml:"mc_version" json:"mc_version"`
+	LoaderVersion    string `yaml:"loader_version" json:"loader_version"`
+	InstallerVersion string `yaml:"installer_version" json:"installer_version"`
+}
+
+type LoaderMetaVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"` // Quilt 没有此字段
+	URL     string `json:"url"`    // 仅安装器有此字段
+}
+
+func (p loaderProvider) Name() string {
+	return p.name
+}
+
+func (p loaderProvider) list(ctx context.Context, kind string) ([]LoaderMetaVersion, error) {
+	return cached(p.metaURL+"/versions/"+kind, func() ([]LoaderMetaVersion, error) {
+		var versions []LoaderMetaVersion
+		err := getJSON(ctx, p.name, p.metaURL+"/versions/"+kind, &versions)
+		return versions, err
+	})
+}
+
+// stable Quilt 没有 stable 字段, 以版本号判断
+func (p loaderProvider) stable(v LoaderMetaVersion) bool {
+	if p.name == "quilt" {
+		return !strings.Contains(v.Version, "-")
+	}
+	return v.Stable
+}
+
+func (p loaderProvider) Projects(context.Context) ([]Project, error) {
+	homepage := "https://fabricmc.net/"
+	if p.name == "quilt" {
+		homepage = "https://quiltmc.org/"
+	}
+	return []Project{{ID: p.name, Name: p.name, Homepage: homepage, Recommend: true}}, nil
+}
+
+func (p loaderProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	if project != p.name {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	games, err := p.list(ctx, "game")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(games))
+	for _, v := range games {
+		versions = append(versions, Version{ID: v.Version, Stable: v.Stable})
+	}
+	return versions, nil
+}
+
+func (p loaderProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	if project != p.name {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	if version == "" {
+		return nil, MCSTErrors.ErrVersionRequired
+	}
+	loaders, err := p.list(ctx, "loader")
+	if err != nil {
+		return nil, err
+	}
+	builds := make([]Build, 0, len(loaders))
+	for _, v := range loaders {
+		builds = append(builds, Build{ID: v.Version, Stable: p.stable(v)})
+	}
+	return builds, nil
+}
+
+// pick 从 kind 列表中选择 version, 为空时选择第一个稳定版(列表按从新到旧排列)
+func (p loaderProvider) pick(ctx context.Context, kind, version string) (LoaderMetaVersion, error) {
+	versions, err := p.list(ctx, kind)
+	if err != nil {
+		return LoaderMetaVersion{}, err
+	}
+	for _, v := range versions {
+		if (version == "" && p.stable(v)) || v.Version == version {
+			return v, nil
+		}
+	}
+	return LoaderMetaVersion{}, fmt.Errorf("%w: %s %s", MCSTErrors.ErrCoreNotFound, kind, version)
+}
+
+func (p loaderProvider) Resolve(ctx context.Context, project, version, build string) (Artifact, error) {
+	if project != p.name {
+		return Artifact{}, MCSTErrors.ErrCoreNotFound
+	}
+	if version == "" {
+		return Artifact{}, MCSTErrors.ErrVersionRequired
+	}
+	if _, err := p.pick(ctx, "game", version); err != nil {
+		return Artifact{}, err
+	}
+	loaderVersion, installerVersion, _ := strings.Cut(build, ":")
+	loader, err := p.pick(ctx, "loader", loaderVersion)
+	if err != nil {
+		return Artifact{}, err
+	}
+	installer, err := p.pick(ctx, "installer", installerVersion)
+	if err != nil {
+		return Artifact{}, err
+	}
+	artifact := Artifact{
+		ExtrasData: LoaderExtras{
+			Loader:           p.name,
+			MinecraftVersion: version,
+			LoaderVersion:    loader.Version,
+			InstallerVersion: installer.Version,
+		},
+	}
+	if !p.installer {
+		artifact.URL = fmt.Sprintf("%s/versions/loader/%s/%s/%s/server/jar", p.metaURL, version, loader.Version, installer.Version)
+		return artifact, nil
+	}
+	artifact.URL = installer.URL
+	artifact.Installer = &configs.Installer{
+		Args: []string{"install", "server", version, loader.Version, "--download-server", "--install-dir=."},
+		Jar:  "quilt-server-launch.jar",
+	}
+	return artifact, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	"github.com/Arama0517/MCST/internal/configs"
+)
+
+func newLoaderFixture(t *testing.T, installerURL string) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/versions/game", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"version": "1.20.2-pre1", "stable": false}, {"version": "1.20.1", "stable": true}]`)
+	})
+	mux.HandleFunc("/versions/loader", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"version": "0.21.0-beta.1", "stable": false}, {"version": "0.20.2", "stable": true}, {"version": "0.20.1", "stable": true}]`)
+	})
+	mux.HandleFunc("/versions/installer", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `[{"version": "1.0.1", "stable": true, "url": %q}, {"version": "1.0.0", "stable": true, "url": ""}]`, installerURL)
+	})
+	return server
+}
+
+func TestFabricProvider(t *testing.T) {
+	server := newLoaderFixture(t, "")
+	provider := api.NewFabricProvider(server.URL)
+
+	artifact, err := provider.Resolve(context.Background(), "fabric", "1.20.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != server.URL+"/versions/loader/1.20.1/0.20.2/1.0.1/server/jar" || artifact.Installer != nil {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+
+	artifact, err = provider.Resolve(context.Background(), "fabric", "1.20.1", "0.20.1:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var extras api.LoaderExtras
+	if err = (configs.Core{ExtrasData: artifact.ExtrasData}).Extras(&extras); err != nil {
+		t.Fatal(err)
+	}
+	if extras != (api.LoaderExtras{Loader: "fabric", MinecraftVersion: "1.20.1", LoaderVersion: "0.20.1", InstallerVersion: "1.0.0"}) {
+		t.Fatalf("extras = %+v", extras)
+	}
+
+	if _, err = provider.Resolve(context.Background(), "fabric", "1.19", ""); err == nil {
+		t.Fatal("不存在的Minecraft版本应该返回错误")
+	}
+}
+
+func TestQuiltProvider(t *testing.T) {
+	server := newLoaderFixture(t, "https://maven.example.com/quilt-installer-1.0.1.jar")
+	provider := api.NewQuiltProvider(server.URL)
+
+	artifact, err := provider.Resolve(context.Background(), "quilt", "1.20.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != "https://maven.example.com/quilt-installer-1.0.1.jar" {
+		t.Fatalf("URL = %q", artifact.URL)
+	}
+	if artifact.Installer == nil || artifact.Installer.Jar != "quilt-server-launch.jar" {
+		t.Fatalf("Installer = %+v", artifact.Installer)
+	}
+	// Quilt 没有 stable 字段, 带有 "-" 的版本不是稳定版
+	if extras := artifact.ExtrasData.(api.LoaderExtras); extras.LoaderVersion != "0.20.2" {
+		t.Fatalf("extras = %+v", extras)
+	}
+}
@@ -19,35 +19,28 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"time"
 
-	"github.com/Arama0517/MCST/internal/download"
-	"github.com/Arama0517/MCST/internal/requests"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 )
 
-func GetFastMirrorData() (map[string]FastMirrorData, error) {
-	req, err := requests.NewRequest(http.MethodGet, "https://download.fastmirror.net/api/v3", nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+// FastMirrorURL 无极镜像, API 位于 /api/v3, 下载位于 /download
+const FastMirrorURL = "https://download.fastmirror.net"
+
+// fastMirrorPageSize 无极镜像每页最多返回的构建数量
+const fastMirrorPageSize = 25
+
+func GetFastMirrorData(ctx context.Context) (map[string]FastMirrorData, error) {
+	return getFastMirrorData(ctx, FastMirrorURL)
+}
+
+func getFastMirrorData(ctx context.Context, baseURL string) (map[string]FastMirrorData, error) {
 	var data struct {
 		Data []FastMirrorData `json:"data"`
 	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if err = resp.Body.Close(); err != nil {
-		return nil, err
-	}
-	if err = json.Unmarshal(body, &data); err != nil {
+	if err := getJSON(ctx, "fastmirror", baseURL+"/api/v3", &data); err != nil {
 		return nil, err
 	}
 	result := map[string]FastMirrorData{}
@@ -57,41 +50,159 @@ func GetFastMirrorData() (map[string]FastMirrorData, error) {
 	return result, nil
 }
 
-func GetFastMirrorBuildsData(core, minecraftVersion string) (map[string]FastMirrorBuilds, error) {
-	req, err := requests.NewRequest(http.MethodGet, fmt.Sprintf("https://download.fastmirror.net/api/v3/%s/%s?offset=0&limit=25", core, minecraftVersion), nil)
+// GetFastMirrorBuildsData 返回所有构建, 会依次请求每一页
+func GetFastMirrorBuildsData(ctx context.Context, core, minecraftVersion string) (map[string]FastMirrorBuilds, error) {
+	builds, err := getFastMirrorAllBuilds(ctx, FastMirrorURL, core, minecraftVersion)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	parseData := map[string]FastMirrorBuilds{}
+	for _, data := range builds {
+		parseData[data.CoreVersion] = data
 	}
+	return parseData, nil
+}
+
+// GetFastMirrorBuildsPage 返回一页构建和构建的总数
+func GetFastMirrorBuildsPage(ctx context.Context, core, minecraftVersion string, offset, limit int) ([]FastMirrorBuilds, int, error) {
+	return getFastMirrorBuildsPage(ctx, FastMirrorURL, core, minecraftVersion, offset, limit)
+}
+
+func getFastMirrorBuildsPage(ctx context.Context, baseURL, core, minecraftVersion string, offset, limit int) ([]FastMirrorBuilds, int, error) {
 	var data struct {
 		Data struct {
 			Builds []FastMirrorBuilds `json:"builds"`
+			Count  int                `json:"count"`
 		} `json:"data"`
 	}
-	body, err := io.ReadAll(resp.Body)
+	url := fmt.Sprintf("%s/api/v3/%s/%s?offset=%d&limit=%d", baseURL, core, minecraftVersion, offset, limit)
+	if err := getJSON(ctx, "fastmirror", url, &data); err != nil {
+		return nil, 0, err
+	}
+	return data.Data.Builds, data.Data.Count, nil
+}
+
+func getFastMirrorAllBuilds(ctx context.Context, baseURL, core, minecraftVersion string) ([]FastMirrorBuilds, error) {
+	var builds []FastMirrorBuilds
+	for {
+		page, count, err := getFastMirrorBuildsPage(ctx, baseURL, core, minecraftVersion, len(builds), fastMirrorPageSize)
+		if err != nil {
+			return nil, err
+		}
+		builds = append(builds, page...)
+		if len(page) < fastMirrorPageSize || len(builds) >= count {
+			return builds, nil
+		}
+	}
+}
+
+func init() {
+	Register(NewFastMirrorProvider(FastMirrorURL))
+}
+
+// NewFastMirrorProvider 返回使用 baseURL 的无极镜像来源
+//
+// 构建版本可以使用 latest, 表示更新时间最新的构建
+func NewFastMirrorProvider(baseURL string) Provider {
+	return fastMirrorProvider{baseURL: baseURL}
+}
+
+// fastMirrorProvider 无极镜像 <https://www.fastmirror.net/>
+type fastMirrorProvider struct {
+	baseURL string
+}
+
+func (fastMirrorProvider) Name() string {
+	return "fastmirror"
+}
+
+func (fastMirrorProvider) Aliases() []string {
+	return []string{"fm"}
+}
+
+func (p fastMirrorProvider) Projects(ctx context.Context) ([]Project, error) {
+	data, err := getFastMirrorData(ctx, p.baseURL)
 	if err != nil {
 		return nil, err
 	}
-	if err = resp.Body.Close(); err != nil {
+	projects := make([]Project, 0, len(data))
+	for _, v := range data {
+		projects = append(projects, Project{
+			ID:          v.Name,
+			Name:        v.Name,
+			Description: v.Tag,
+			Homepage:    v.Homepage,
+			Recommend:   v.Recommend,
+		})
+	}
+	return projects, nil
+}
+
+func (p fastMirrorProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	data, err := getFastMirrorData(ctx, p.baseURL)
+	if err != nil {
 		return nil, err
 	}
-	err = json.Unmarshal(body, &data)
+	core, ok := data[project]
+	if !ok {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	versions := make([]Version, 0, len(core.MinecraftVersions))
+	for _, v := range core.MinecraftVersions {
+		versions = append(versions, Version{ID: v, Stable: isReleaseVersion(v)})
+	}
+	return versions, nil
+}
+
+func (p fastMirrorProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	if version == "" {
+		return nil, MCSTErrors.ErrVersionRequired
+	}
+	data, err := getFastMirrorAllBuilds(ctx, p.baseURL, project, version)
 	if err != nil {
 		return nil, err
 	}
-	parseData := map[string]FastMirrorBuilds{}
-	for i := 0; i < len(data.Data.Builds); i++ {
-		data := data.Data.Builds[i]
-		parseData[data.CoreVersion] = data
+	return fastMirrorBuilds(data), nil
+}
+
+// BuildsPage 实现 [Pager]
+func (p fastMirrorProvider) BuildsPage(ctx context.Context, project, version string, offset, limit int) ([]Build, int, error) {
+	if version == "" {
+		return nil, 0, MCSTErrors.ErrVersionRequired
 	}
-	return parseData, nil
+	data, count, err := getFastMirrorBuildsPage(ctx, p.baseURL, project, version, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return fastMirrorBuilds(data), count, nil
 }
 
-func GetFastMirrorDownloader(core, minecraftVersion, buildVersion string) *download.Downloader {
-	return download.NewDownloader(fmt.Sprintf("https://download.fastmirror.net/download/%s/%s/%s", core, minecraftVersion, buildVersion))
+func fastMirrorBuilds(data []FastMirrorBuilds) []Build {
+	builds := make([]Build, 0, len(data))
+	for _, v := range data {
+		build := Build{ID: v.CoreVersion, Description: v.Name, SHA1: v.Sha1}
+		if updateTime, err := time.Parse("2006-01-02T15:04:05", v.UpdateTime); err == nil {
+			build.Time = updateTime
+		}
+		builds = append(builds, build)
+	}
+	return builds
+}
+
+func (p fastMirrorProvider) Resolve(ctx context.Context, project, version, build string) (Artifact, error) {
+	b, err := FindBuild(ctx, p, project, version, build)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{
+		URL:  fmt.Sprintf("%s/download/%s/%s/%s", p.baseURL, project, version, b.ID),
+		SHA1: b.SHA1,
+		ExtrasData: map[string]any{
+			"core":          project,
+			"mc_version":    version,
+			"build_version": b.ID,
+		},
+	}, nil
 }
 
 type FastMirrorData struct {
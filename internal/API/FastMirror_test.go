@@ -0,0 +1,109 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/Arama0517/MCST/internal/API"
+)
+
+// newFastMirrorFixture 模拟有 count 个构建的 Mohist 1.20.1, build<count-1> 最新; 每页最多返回 25 个
+func newFastMirrorFixture(t *testing.T, count int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/Mohist/1.20.1" {
+			http.NotFound(w, r)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		limit = min(limit, 25)
+		var builds []api.FastMirrorBuilds
+		start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := count - 1 - offset; i >= 0 && len(builds) < limit; i-- {
+			builds = append(builds, api.FastMirrorBuilds{
+				Name:        "Mohist",
+				CoreVersion: fmt.Sprintf("build%d", i),
+				UpdateTime:  start.Add(time.Duration(i) * time.Hour).Format("2006-01-02T15:04:05"),
+				Sha1:        fmt.Sprintf("sha1-%d", i),
+			})
+		}
+		var data struct {
+			Data struct {
+				Builds []api.FastMirrorBuilds `json:"builds"`
+				Offset int                    `json:"offset"`
+				Limit  int                    `json:"limit"`
+				Count  int                    `json:"count"`
+			} `json:"data"`
+		}
+		data.Data.Builds, data.Data.Offset, data.Data.Limit, data.Data.Count = builds, offset, limit, count
+		_ = json.NewEncoder(w).Encode(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFastMirrorPagination(t *testing.T) {
+	server := newFastMirrorFixture(t, 60)
+	provider := api.NewFastMirrorProvider(server.URL)
+
+	builds, err := provider.Builds(context.Background(), "Mohist", "1.20.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 60 {
+		t.Fatalf("应该获取所有 60 个构建, 实际 %d 个", len(builds))
+	}
+
+	// 旧的构建也可以下载
+	artifact, err := provider.Resolve(context.Background(), "Mohist", "1.20.1", "build0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.SHA1 != "sha1-0" {
+		t.Fatalf("SHA1 = %q", artifact.SHA1)
+	}
+
+	build, err := api.ResolveBuild(context.Background(), provider, "Mohist", "1.20.1", api.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact, err = provider.Resolve(context.Background(), "Mohist", "1.20.1", build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != server.URL+"/download/Mohist/1.20.1/build59" {
+		t.Fatalf("URL = %q", artifact.URL)
+	}
+
+	page, count, err := api.BuildsPage(context.Background(), provider, "Mohist", "1.20.1", 50, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 60 || len(page) != 10 || page[0].ID != "build9" {
+		t.Fatalf("count = %d, page = %+v", count, page)
+	}
+}
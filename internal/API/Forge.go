@@ -0,0 +1,194 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+const (
+	ForgeMavenURL    = "https://maven.minecraftforge.net/net/minecraftforge/forge"
+	NeoForgeMavenURL = "https://maven.neoforged.net/releases/net/neoforged/neoforge"
+)
+
+func init() {
+	Register(NewForgeProvider(ForgeMavenURL))
+	Register(NewNeoForgeProvider(NeoForgeMavenURL))
+}
+
+// NewForgeProvider 返回使用 mavenURL 的 Forge 来源; Maven 中的版本格式为 "<Minecraft版本>-<Forge版本>"
+func NewForgeProvider(mavenURL string) Provider {
+	return forgeProvider{
+		name:     "forge",
+		mavenURL: mavenURL,
+		split: func(version string) (string, string, bool) {
+			return strings.Cut(version, "-")
+		},
+	}
+}
+
+var neoForgeVersion = regexp.MustCompile(`^(\d+)\.(\d+)\.`)
+
+// NewNeoForgeProvider 返回使用 mavenURL 的 NeoForge 来源; NeoForge 版本的前两位对应 Minecraft 版本, 例如 20.4.80 对应 1.20.4, 21.0.1 对应 1.21
+func NewNeoForgeProvider(mavenURL string) Provider {
+	return forgeProvider{
+		name:     "neoforge",
+		mavenURL: mavenURL,
+		split: func(version string) (string, string, bool) {
+			match := neoForgeVersion.FindStringSubmatch(version)
+			if match == nil {
+				return "", "", false
+			}
+			if match[2] == "0" {
+				return "1." + match[1], version, true
+			}
+			return "1." + match[1] + "." + match[2], version, true
+		},
+	}
+}
+
+// forgeProvider 下载的是安装器, 创建服务器时使用 --installServer 运行
+type forgeProvider struct {
+	name     string
+	mavenURL string
+	// split 把 Maven 中的版本拆分为Minecraft版本和构建版本
+	split func(version string) (minecraftVersion, build string, ok bool)
+}
+
+// ForgeExtras 保存在核心 ExtrasData 中的数据, 使用 [configs.Core.Extras] 读取
+type ForgeExtras struct {
+	Loader           string `yaml:"loader" json:"loader"`
+	MinecraftVersion string `yaml:"mc_version" json:"mc_version"`
+	LoaderVersion    string `yaml:"loader_version" json:"loader_version"`
+}
+
+type mavenMetadata struct {
+	Versions []string `xml:"versioning>versions>version"`
+}
+
+func (p forgeProvider) Name() string {
+	return p.name
+}
+
+// versions 返回 Maven 中的所有版本, 按Minecraft版本分组
+func (p forgeProvider) versions(ctx context.Context) (map[string][]string, error) {
+	return cached(p.mavenURL+"/maven-metadata.xml", func() (map[string][]string, error) {
+		body, err := get(ctx, p.name, p.mavenURL+"/maven-metadata.xml")
+		if err != nil {
+			return nil, err
+		}
+		var metadata mavenMetadata
+		if err = xml.Unmarshal(body, &metadata); err != nil {
+			return nil, err
+		}
+		result := map[string][]string{}
+		for _, version := range metadata.Versions {
+			if minecraftVersion, build, ok := p.split(version); ok {
+				result[minecraftVersion] = append(result[minecraftVersion], build)
+			}
+		}
+		return result, nil
+	})
+}
+
+// mavenVersion 把Minecraft版本和构建版本还原为 Maven 中的版本
+func (p forgeProvider) mavenVersion(minecraftVersion, build string) string {
+	if p.name == "forge" {
+		return minecraftVersion + "-" + build
+	}
+	return build
+}
+
+func (p forgeProvider) Projects(context.Context) ([]Project, error) {
+	homepage := "https://files.minecraftforge.net/"
+	if p.name == "neoforge" {
+		homepage = "https://neoforged.net/"
+	}
+	return []Project{{ID: p.name, Name: p.name, Homepage: homepage, Recommend: true}}, nil
+}
+
+func (p forgeProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	if project != p.name {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	data, err := p.versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(data))
+	for version := range data {
+		versions = append(versions, Version{ID: version, Stable: isReleaseVersion(version)})
+	}
+	return versions, nil
+}
+
+func (p forgeProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	if project != p.name {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	if version == "" {
+		return nil, MCSTErrors.ErrVersionRequired
+	}
+	data, err := p.versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	builds := make([]Build, 0, len(data[version]))
+	for _, build := range data[version] {
+		// 测试版带有 -beta 等后缀
+		builds = append(builds, Build{ID: build, Stable: !strings.Contains(build, "-")})
+	}
+	return builds, nil
+}
+
+func (p forgeProvider) Resolve(ctx context.Context, project, version, build string) (Artifact, error) {
+	if _, err := FindBuild(ctx, p, project, version, build); err != nil {
+		return Artifact{}, err
+	}
+	mavenVersion := p.mavenVersion(version, build)
+	url := fmt.Sprintf("%s/%s/%s-%s-installer.jar", p.mavenURL, mavenVersion, p.name, mavenVersion)
+	// Maven 仓库中每个文件都有对应的 .sha1, 获取失败时不校验
+	var sha1 string
+	if body, err := get(ctx, p.name, url+".sha1"); err == nil {
+		if fields := strings.Fields(string(body)); len(fields) > 0 {
+			sha1 = fields[0]
+		}
+	}
+	return Artifact{
+		URL:  url,
+		SHA1: sha1,
+		Installer: &configs.Installer{
+			Args: []string{"--installServer"},
+			// 1.17 之前的 Forge 会生成可以直接启动的jar, 之后的版本生成参数文件
+			Jar: p.name + "-*.jar",
+		},
+		ExtrasData: ForgeExtras{
+			Loader:           p.name,
+			MinecraftVersion: version,
+			LoaderVersion:    build,
+		},
+	}, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+)
+
+func newMavenFixture(t *testing.T, versions ...string) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/maven-metadata.xml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><metadata><versioning><versions>`)
+		for _, version := range versions {
+			_, _ = fmt.Fprintf(w, "<version>%s</version>", version)
+		}
+		_, _ = fmt.Fprint(w, `</versions></versioning></metadata>`)
+	})
+	mux.HandleFunc("/1.20.1-47.1.0/forge-1.20.1-47.1.0-installer.jar.sha1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "da39a3ee5e6b4b0d3255bfef95601890afd80709\n")
+	})
+	return server
+}
+
+func TestForgeProvider(t *testing.T) {
+	server := newMavenFixture(t, "1.20.1-47.1.0", "1.20.1-47.1.3", "1.19.2-43.2.0")
+	provider := api.NewForgeProvider(server.URL)
+
+	builds, err := provider.Builds(context.Background(), "forge", "1.20.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 2 || builds[0].ID != "47.1.0" {
+		t.Fatalf("builds = %+v", builds)
+	}
+	artifact, err := provider.Resolve(context.Background(), "forge", "1.20.1", "47.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != server.URL+"/1.20.1-47.1.0/forge-1.20.1-47.1.0-installer.jar" {
+		t.Fatalf("URL = %q", artifact.URL)
+	}
+	if artifact.SHA1 != "da39a3ee5e6b4b0d3255bfef95601890afd80709" {
+		t.Fatalf("SHA1 = %q", artifact.SHA1)
+	}
+	if artifact.Installer == nil || artifact.Installer.Args[0] != "--installServer" {
+		t.Fatalf("Installer = %+v", artifact.Installer)
+	}
+}
+
+func TestNeoForgeProvider(t *testing.T) {
+	server := newMavenFixture(t, "20.4.80-beta", "20.4.237", "21.0.167")
+	provider := api.NewNeoForgeProvider(server.URL)
+
+	for version, want := range map[string]int{"1.20.4": 2, "1.21": 1, "1.21.1": 0} {
+		builds, err := provider.Builds(context.Background(), "neoforge", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(builds) != want {
+			t.Fatalf("%s: builds = %+v", version, builds)
+		}
+	}
+	artifact, err := provider.Resolve(context.Background(), "neoforge", "1.20.4", "20.4.237")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有 .sha1 时不校验
+	if artifact.URL != server.URL+"/20.4.237/neoforge-20.4.237-installer.jar" || artifact.SHA1 != "" {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// MojangManifestURL 官方的版本清单
+const MojangManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+
+func init() {
+	Register(NewMojangProvider(MojangManifestURL))
+}
+
+// NewMojangProvider 返回读取 manifestURL 的官方原版服务端来源
+//
+// 只有一个核心 "vanilla", 每个版本只有一个构建, 所以不需要指定 --build_version
+func NewMojangProvider(manifestURL string) Provider {
+	return mojangProvider{manifestURL: manifestURL}
+}
+
+type mojangProvider struct {
+	manifestURL string
+}
+
+type MojangManifest struct {
+	Latest struct {
+		Release  string `json:"release"`
+		Snapshot string `json:"snapshot"`
+	} `json:"latest"`
+	Versions []MojangManifestVersion `json:"versions"`
+}
+
+type MojangManifestVersion struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"` // release, snapshot, old_beta, old_alpha
+	URL         string    `json:"url"`
+	ReleaseTime time.Time `json:"releaseTime"`
+	SHA1        string    `json:"sha1"`
+}
+
+// MojangVersion 每个版本的详细信息, 只解析需要的部分
+type MojangVersion struct {
+	ID          string `json:"id"`
+	JavaVersion struct {
+		MajorVersion int `json:"majorVersion"`
+	} `json:"javaVersion"`
+	Downloads map[string]struct {
+		SHA1 string `json:"sha1"`
+		Size int64  `json:"size"`
+		URL  string `json:"url"`
+	} `json:"downloads"`
+}
+
+func (mojangProvider) Name() string {
+	return "mojang"
+}
+
+func (mojangProvider) Aliases() []string {
+	return []string{"vanilla"}
+}
+
+func (p mojangProvider) manifest(ctx context.Context) (MojangManifest, error) {
+	return cached(p.manifestURL, func() (MojangManifest, error) {
+		var manifest MojangManifest
+		err := getJSON(ctx, "mojang", p.manifestURL, &manifest)
+		return manifest, err
+	})
+}
+
+func (p mojangProvider) findVersion(ctx context.Context, project, version string) (MojangManifestVersion, error) {
+	if project != "vanilla" {
+		return MojangManifestVersion{}, MCSTErrors.ErrCoreNotFound
+	}
+	if version == "" {
+		return MojangManifestVersion{}, MCSTErrors.ErrVersionRequired
+	}
+	manifest, err := p.manifest(ctx)
+	if err != nil {
+		return MojangManifestVersion{}, err
+	}
+	for _, v := range manifest.Versions {
+		if v.ID == version {
+			return v, nil
+		}
+	}
+	return MojangManifestVersion{}, MCSTErrors.ErrCoreNotFound
+}
+
+func (mojangProvider) Projects(context.Context) ([]Project, error) {
+	return []Project{{
+		ID:          "vanilla",
+		Name:        "Vanilla",
+		Description: "Minecraft: Java Edition",
+		Homepage:    "https://www.minecraft.net/",
+		Recommend:   true,
+	}}, nil
+}
+
+func (p mojangProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	if project != "vanilla" {
+		return nil, MCSTErrors.ErrCoreNotFound
+	}
+	manifest, err := p.manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(manifest.Versions))
+	for _, v := range manifest.Versions {
+		versions = append(versions, Version{ID: v.ID, Stable: v.Type == "release", Time: v.ReleaseTime})
+	}
+	return versions, nil
+}
+
+func (p mojangProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	v, err := p.findVersion(ctx, project, version)
+	if err != nil {
+		return nil, err
+	}
+	return []Build{{
+		ID:          v.ID,
+		Description: v.Type,
+		Time:        v.ReleaseTime,
+		Stable:      v.Type == "release",
+	}}, nil
+}
+
+func (p mojangProvider) Resolve(ctx context.Context, project, version, _ string) (Artifact, error) {
+	v, err := p.findVersion(ctx, project, version)
+	if err != nil {
+		return Artifact{}, err
+	}
+	var detail MojangVersion
+	if err = getJSON(ctx, "mojang", v.URL, &detail); err != nil {
+		return Artifact{}, err
+	}
+	server, ok := detail.Downloads["server"]
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: %s", MCSTErrors.ErrNoServerJar, version)
+	}
+	return Artifact{
+		URL:       server.URL,
+		SHA1:      server.SHA1,
+		JavaMajor: detail.JavaVersion.MajorVersion,
+		ExtrasData: map[string]any{
+			"mc_version": version,
+			"type":       v.Type,
+		},
+	}, nil
+}
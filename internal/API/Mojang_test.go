@@ -0,0 +1,92 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+func newMojangFixture(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/mc/game/version_manifest_v2.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{
+  "latest": {"release": "1.20.1", "snapshot": "23w31a"},
+  "versions": [
+    {"id": "23w31a", "type": "snapshot", "url": "%[1]s/v1/23w31a.json", "releaseTime": "2023-08-01T12:00:00+00:00"},
+    {"id": "1.20.1", "type": "release", "url": "%[1]s/v1/1.20.1.json", "releaseTime": "2023-06-12T13:25:51+00:00"},
+    {"id": "a1.0.4", "type": "old_alpha", "url": "%[1]s/v1/a1.0.4.json", "releaseTime": "2010-07-09T00:00:00+00:00"}
+  ]
+}`, server.URL)
+	})
+	mux.HandleFunc("/v1/1.20.1.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{
+  "id": "1.20.1",
+  "javaVersion": {"component": "java-runtime-gamma", "majorVersion": 17},
+  "downloads": {"server": {"sha1": "84194a2f286ef7c14ed7ce0090dba59902951553", "size": 49150256, "url": "%s/server.jar"}}
+}`, server.URL)
+	})
+	mux.HandleFunc("/v1/a1.0.4.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id": "a1.0.4", "downloads": {"client": {"sha1": "", "size": 0, "url": ""}}}`)
+	})
+	return server
+}
+
+func TestMojangProvider(t *testing.T) {
+	server := newMojangFixture(t)
+	provider := api.NewMojangProvider(server.URL + "/mc/game/version_manifest_v2.json")
+
+	versions, err := provider.Versions(context.Background(), "vanilla")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0].Stable || !versions[1].Stable {
+		t.Fatalf("versions = %+v", versions)
+	}
+
+	artifact, err := provider.Resolve(context.Background(), "vanilla", "1.20.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != server.URL+"/server.jar" {
+		t.Fatalf("URL = %q", artifact.URL)
+	}
+	if artifact.SHA1 != "84194a2f286ef7c14ed7ce0090dba59902951553" {
+		t.Fatalf("SHA1 = %q", artifact.SHA1)
+	}
+	if artifact.JavaMajor != 17 {
+		t.Fatalf("JavaMajor = %d", artifact.JavaMajor)
+	}
+
+	if _, err = provider.Resolve(context.Background(), "vanilla", "a1.0.4", ""); !errors.Is(err, MCSTErrors.ErrNoServerJar) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err = provider.Resolve(context.Background(), "vanilla", "1.0.0", ""); !errors.Is(err, MCSTErrors.ErrCoreNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
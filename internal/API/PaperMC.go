@@ -0,0 +1,176 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// PaperMCAPIURL PaperMC 的 API v2
+const PaperMCAPIURL = "https://api.papermc.io/v2"
+
+func init() {
+	Register(NewPaperMCProvider(PaperMCAPIURL))
+}
+
+// NewPaperMCProvider 返回使用 baseURL 的 PaperMC 来源, 支持 Paper, Folia, Velocity, Waterfall 等项目
+//
+// 不指定构建版本时使用 default 频道的最新构建
+func NewPaperMCProvider(baseURL string) Provider {
+	return paperMCProvider{baseURL: baseURL}
+}
+
+type paperMCProvider struct {
+	baseURL string
+}
+
+// PaperMCExtras 保存在核心 ExtrasData 中的数据, 使用 [configs.Core.Extras] 读取
+type PaperMCExtras struct {
+	Project string `yaml:"project" json:"project"`
+	Version string `yaml:"version" json:"version"`
+	Build   int    `yaml:"build" json:"build"`
+	Channel string `yaml:"channel" json:"channel"`
+}
+
+type PaperMCProject struct {
+	ProjectID     string   `json:"project_id"`
+	ProjectName   string   `json:"project_name"`
+	VersionGroups []string `json:"version_groups"`
+	Versions      []string `json:"versions"`
+}
+
+type PaperMCBuild struct {
+	Build     int       `json:"build"`
+	Time      time.Time `json:"time"`
+	Channel   string    `json:"channel"` // default 或 experimental
+	Promoted  bool      `json:"promoted"`
+	Downloads map[string]struct {
+		Name   string `json:"name"`
+		SHA256 string `json:"sha256"`
+	} `json:"downloads"`
+}
+
+func (paperMCProvider) Name() string {
+	return "papermc"
+}
+
+func (paperMCProvider) Aliases() []string {
+	return []string{"paper"}
+}
+
+func (p paperMCProvider) Projects(ctx context.Context) ([]Project, error) {
+	var data struct {
+		Projects []string `json:"projects"`
+	}
+	if err := getJSON(ctx, "papermc", p.baseURL+"/projects", &data); err != nil {
+		return nil, err
+	}
+	projects := make([]Project, 0, len(data.Projects))
+	for _, project := range data.Projects {
+		projects = append(projects, Project{
+			ID:        project,
+			Name:      project,
+			Homepage:  "https://papermc.io/software/" + project,
+			Recommend: project == "paper",
+		})
+	}
+	return projects, nil
+}
+
+func (p paperMCProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	var data PaperMCProject
+	if err := getJSON(ctx, "papermc", fmt.Sprintf("%s/projects/%s", p.baseURL, project), &data); err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(data.Versions))
+	for _, version := range data.Versions {
+		versions = append(versions, Version{ID: version, Stable: isReleaseVersion(version)})
+	}
+	return versions, nil
+}
+
+func (p paperMCProvider) builds(ctx context.Context, project, version string) ([]PaperMCBuild, error) {
+	if version == "" {
+		return nil, MCSTErrors.ErrVersionRequired
+	}
+	return cached(fmt.Sprintf("%s/projects/%s/versions/%s/builds", p.baseURL, project, version), func() ([]PaperMCBuild, error) {
+		var data struct {
+			Builds []PaperMCBuild `json:"builds"`
+		}
+		err := getJSON(ctx, "papermc", fmt.Sprintf("%s/projects/%s/versions/%s/builds", p.baseURL, project, version), &data)
+		return data.Builds, err
+	})
+}
+
+func (p paperMCProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	data, err := p.builds(ctx, project, version)
+	if err != nil {
+		return nil, err
+	}
+	builds := make([]Build, 0, len(data))
+	for _, b := range data {
+		builds = append(builds, Build{
+			ID:          strconv.Itoa(b.Build),
+			Description: b.Channel,
+			Time:        b.Time,
+			Stable:      b.Channel == "default",
+			SHA256:      b.Downloads["application"].SHA256,
+		})
+	}
+	return builds, nil
+}
+
+func (p paperMCProvider) Resolve(ctx context.Context, project, version, build string) (Artifact, error) {
+	data, err := p.builds(ctx, project, version)
+	if err != nil {
+		return Artifact{}, err
+	}
+	var selected *PaperMCBuild
+	for i := range data {
+		b := &data[i]
+		switch {
+		case build == "" && b.Channel == "default" && (selected == nil || b.Build > selected.Build):
+			selected = b
+		case build != "" && strconv.Itoa(b.Build) == build:
+			selected = b
+		}
+	}
+	if selected == nil {
+		return Artifact{}, MCSTErrors.ErrCoreNotFound
+	}
+	application, ok := selected.Downloads["application"]
+	if !ok {
+		return Artifact{}, MCSTErrors.ErrCoreNotFound
+	}
+	return Artifact{
+		URL:    fmt.Sprintf("%s/projects/%s/versions/%s/builds/%d/downloads/%s", p.baseURL, project, version, selected.Build, application.Name),
+		SHA256: application.SHA256,
+		ExtrasData: PaperMCExtras{
+			Project: project,
+			Version: version,
+			Build:   selected.Build,
+			Channel: selected.Channel,
+		},
+	}, nil
+}
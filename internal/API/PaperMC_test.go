@@ -0,0 +1,66 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	"github.com/Arama0517/MCST/internal/configs"
+)
+
+func TestPaperMCProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/v2/projects/paper/versions/1.20.1/builds", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"builds": [
+  {"build": 195, "time": "2023-09-01T00:00:00Z", "channel": "default", "downloads": {"application": {"name": "paper-1.20.1-195.jar", "sha256": "aaaa"}}},
+  {"build": 196, "time": "2023-09-02T00:00:00Z", "channel": "default", "downloads": {"application": {"name": "paper-1.20.1-196.jar", "sha256": "bbbb"}}},
+  {"build": 197, "time": "2023-09-03T00:00:00Z", "channel": "experimental", "downloads": {"application": {"name": "paper-1.20.1-197.jar", "sha256": "cccc"}}}
+]}`)
+	})
+	provider := api.NewPaperMCProvider(server.URL + "/v2")
+
+	// 不指定构建版本时使用 default 频道的最新构建
+	artifact, err := provider.Resolve(context.Background(), "paper", "1.20.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.SHA256 != "bbbb" || artifact.URL != server.URL+"/v2/projects/paper/versions/1.20.1/builds/196/downloads/paper-1.20.1-196.jar" {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+
+	// ExtrasData 保存到配置文件再读取后仍然可以解析为 PaperMCExtras
+	artifact, err = provider.Resolve(context.Background(), "paper", "1.20.1", "197")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var extras api.PaperMCExtras
+	if err = (configs.Core{ExtrasData: artifact.ExtrasData}).Extras(&extras); err != nil {
+		t.Fatal(err)
+	}
+	if extras != (api.PaperMCExtras{Project: "paper", Version: "1.20.1", Build: 197, Channel: "experimental"}) {
+		t.Fatalf("extras = %+v", extras)
+	}
+}
@@ -19,31 +19,16 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
 
-	"github.com/Arama0517/MCST/internal/requests"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 )
 
-func GetPolarsData() (map[string]PolarsData, error) {
-	req, err := requests.NewRequest(http.MethodGet, "https://mirror.polars.cc/api/query/minecraft/core", nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
+func GetPolarsData(ctx context.Context) (map[string]PolarsData, error) {
 	var data []PolarsData
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(body, &data)
-	if err != nil {
+	if err := getJSON(ctx, "polars", "https://mirror.polars.cc/api/query/minecraft/core", &data); err != nil {
 		return nil, err
 	}
 	result := map[string]PolarsData{}
@@ -51,40 +36,93 @@ func GetPolarsData() (map[string]PolarsData, error) {
 		data := data[i]
 		result[data.Name] = data
 	}
-	if err := resp.Body.Close(); err != nil {
+	return result, nil
+}
+
+func GetPolarsCoresData(ctx context.Context, id int) (map[int]PolarsCores, error) {
+	var data []PolarsCores
+	if err := getJSON(ctx, "polars", fmt.Sprintf("https://mirror.polars.cc/api/query/minecraft/core/%d", id), &data); err != nil {
 		return nil, err
 	}
-	return result, nil
+	parsedData := map[int]PolarsCores{}
+	for i := 0; i < len(data); i++ {
+		data := data[i]
+		parsedData[data.ID] = data
+	}
+	return parsedData, nil
+}
+
+func init() {
+	Register(polarsProvider{})
 }
 
-func GetPolarsCoresData(id int) (map[int]PolarsCores, error) {
-	req, err := requests.NewRequest(http.MethodGet, fmt.Sprintf("https://mirror.polars.cc/api/query/minecraft/core/%d", id), nil)
+// polarsProvider 极星云镜像 <https://mirror.polars.cc/>; 没有Minecraft版本, project 为核心类型ID, build 为核心ID
+type polarsProvider struct{}
+
+func (polarsProvider) Name() string {
+	return "polars"
+}
+
+func (polarsProvider) Projects(ctx context.Context) ([]Project, error) {
+	data, err := GetPolarsData(ctx)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	projects := make([]Project, 0, len(data))
+	for _, v := range data {
+		projects = append(projects, Project{
+			ID:          strconv.Itoa(v.ID),
+			Name:        v.Name,
+			Description: v.Description,
+		})
+	}
+	return projects, nil
+}
+
+func (polarsProvider) Versions(context.Context, string) ([]Version, error) {
+	return nil, nil
+}
+
+func (polarsProvider) Builds(ctx context.Context, project, _ string) ([]Build, error) {
+	typeID, err := strconv.Atoi(project)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
-	var data []PolarsCores
-	body, err := io.ReadAll(resp.Body)
+	data, err := GetPolarsCoresData(ctx, typeID)
 	if err != nil {
 		return nil, err
 	}
-	err = json.Unmarshal(body, &data)
+	builds := make([]Build, 0, len(data))
+	for _, v := range data {
+		builds = append(builds, Build{ID: strconv.Itoa(v.ID), Description: v.Name})
+	}
+	return builds, nil
+}
+
+func (polarsProvider) Resolve(ctx context.Context, project, _, build string) (Artifact, error) {
+	typeID, err := strconv.Atoi(project)
+	if err != nil {
+		return Artifact{}, err
+	}
+	coreID, err := strconv.Atoi(build)
 	if err != nil {
-		return nil, err
+		return Artifact{}, err
 	}
-	parsedData := map[int]PolarsCores{}
-	for i := 0; i < len(data); i++ {
-		data := data[i]
-		parsedData[data.ID] = data
+	data, err := GetPolarsCoresData(ctx, typeID)
+	if err != nil {
+		return Artifact{}, err
 	}
-	if err := resp.Body.Close(); err != nil {
-		return nil, err
+	core, ok := data[coreID]
+	if !ok {
+		return Artifact{}, MCSTErrors.ErrCoreNotFound
 	}
-	return parsedData, nil
+	return Artifact{
+		URL: core.DownloadURL,
+		ExtrasData: map[string]int{
+			"type_id": typeID,
+			"core_id": coreID,
+		},
+	}, nil
 }
 
 type PolarsData struct {
@@ -0,0 +1,101 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/requests"
+	"github.com/apex/log"
+)
+
+// Refresh 由 --refresh 设置, 为 true 时忽略未过期的缓存
+var Refresh bool
+
+// cachePath 返回 url 在缓存目录中的位置, 文件的修改时间就是获取的时间; 缓存目录未初始化时返回空字符串
+func cachePath(name, url string) string {
+	if configs.CacheDir == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(configs.CacheDir, name, hex.EncodeToString(hash[:]))
+}
+
+// get 请求 url 并返回响应的内容; 缓存未过期时直接使用缓存, 离线模式下只使用缓存
+//
+// 请求失败时如果有过期的缓存, 会使用过期的缓存
+func get(ctx context.Context, name, url string) ([]byte, error) {
+	path := cachePath(name, url)
+	var cached []byte
+	var cachedAt time.Time
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			if cached, err = os.ReadFile(path); err == nil {
+				cachedAt = info.ModTime()
+			}
+		}
+	}
+	fresh := !cachedAt.IsZero() && time.Since(cachedAt) < configs.Configs.Settings.Cache.TTLFor(name)
+	switch {
+	case requests.Offline && cachedAt.IsZero():
+		return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrNotCached, url)
+	case requests.Offline, fresh && !Refresh:
+		return cached, nil
+	}
+
+	body, err := fetch(ctx, url)
+	if err != nil {
+		// 被取消时不使用过期的缓存, 直接退出
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if !cachedAt.IsZero() && !errors.Is(err, MCSTErrors.ErrBadStatus) {
+			log.WithError(err).WithField("cached_at", cachedAt.Format(time.DateTime)).Warn("请求失败, 使用过期的缓存")
+			return cached, nil
+		}
+		return nil, err
+	}
+	if path != "" {
+		if err = saveCache(path, body); err != nil {
+			log.WithError(err).Debug("无法写入缓存")
+		}
+	}
+	return body, nil
+}
+
+func saveCache(path string, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// 先写入临时文件, 避免中断后留下不完整的缓存
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
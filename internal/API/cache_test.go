@@ -0,0 +1,96 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/requests"
+)
+
+func TestMetadataCache(t *testing.T) {
+	cacheDir, settings := configs.CacheDir, configs.Configs.Settings.Cache
+	t.Cleanup(func() {
+		configs.CacheDir, configs.Configs.Settings.Cache = cacheDir, settings
+		api.Refresh, requests.Offline = false, false
+	})
+	configs.CacheDir = t.TempDir()
+	configs.Configs.Settings.Cache = configs.Cache{TTL: "1h", Providers: map[string]string{}}
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requestCount++
+		_, _ = w.Write([]byte(`{"data": [{"name": "Paper", "mc_versions": ["1.20.1"]}]}`))
+	}))
+	provider := api.NewFastMirrorProvider(server.URL)
+	projects := func() {
+		t.Helper()
+		result, err := provider.Projects(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 1 || result[0].ID != "Paper" {
+			t.Fatalf("projects = %+v", result)
+		}
+	}
+
+	projects()
+	projects()
+	if requestCount != 1 {
+		t.Fatalf("请求了 %d 次, 未过期时应该使用缓存", requestCount)
+	}
+
+	api.Refresh = true
+	projects()
+	api.Refresh = false
+	if requestCount != 2 {
+		t.Fatalf("请求了 %d 次, --refresh 应该忽略缓存", requestCount)
+	}
+
+	configs.Configs.Settings.Cache.Providers["fastmirror"] = "0s"
+	projects()
+	if requestCount != 3 {
+		t.Fatalf("请求了 %d 次, 过期后应该重新请求", requestCount)
+	}
+
+	// 请求失败时使用过期的缓存
+	server.Close()
+	projects()
+
+	// 取消后不使用过期的缓存
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Projects(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消后 err = %v", err)
+	}
+
+	requests.Offline = true
+	projects()
+	_, err := api.NewFastMirrorProvider(server.URL + "/other").Projects(context.Background())
+	if !errors.Is(err, MCSTErrors.ErrNotCached) {
+		t.Fatalf("err = %v", err)
+	}
+}
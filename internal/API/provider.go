@@ -0,0 +1,258 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/requests"
+)
+
+// Project 核心的种类, 例如 Paper, Mohist
+type Project struct {
+	ID          string `json:"id" yaml:"id"` // 下载时使用的标识
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	Homepage    string `json:"homepage" yaml:"homepage"`
+	Recommend   bool   `json:"recommend" yaml:"recommend"`
+}
+
+// Version Minecraft版本
+type Version struct {
+	ID     string    `json:"id" yaml:"id"`
+	Stable bool      `json:"stable" yaml:"stable"` // 正式版为 true, 快照或测试版为 false
+	Time   time.Time `json:"time" yaml:"time"`     // 发布时间, 未知时为零值
+}
+
+// Build 某个Minecraft版本的一次构建
+type Build struct {
+	ID          string    `json:"id" yaml:"id"`
+	Description string    `json:"description" yaml:"description"`
+	Time        time.Time `json:"time" yaml:"time"` // 未知时为零值
+	Stable      bool      `json:"stable" yaml:"stable"`
+	SHA1        string    `json:"sha1" yaml:"sha1"`
+	SHA256      string    `json:"sha256" yaml:"sha256"`
+}
+
+// Artifact 解析后的下载信息
+type Artifact struct {
+	URL        string
+	SHA1       string             // 为空时不校验
+	SHA256     string             // 为空时不校验
+	JavaMajor  int                // 需要的Java主版本, 0为未知
+	Installer  *configs.Installer // 不为空时下载的是安装器
+	ExtrasData any                // 保存到核心中的其他数据
+}
+
+// Provider 核心的来源; 没有Minecraft版本概念的来源 Versions 返回 nil, Builds 的 version 参数为空
+//
+// 所有方法都会在 ctx 取消后尽快返回
+type Provider interface {
+	Name() string
+	Projects(ctx context.Context) ([]Project, error)
+	Versions(ctx context.Context, project string) ([]Version, error)
+	Builds(ctx context.Context, project, version string) ([]Build, error)
+	Resolve(ctx context.Context, project, version, build string) (Artifact, error)
+}
+
+// Pager 可选接口, 可以只获取一页构建的来源实现它, 返回这一页的构建和构建的总数
+type Pager interface {
+	BuildsPage(ctx context.Context, project, version string, offset, limit int) ([]Build, int, error)
+}
+
+// Aliaser 可选接口, 为 'MCST download <provider>' 提供别名
+type Aliaser interface {
+	Aliases() []string
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register 注册一个来源, 注册后会自动生成 'MCST download <name>' 命令
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = &cachedProvider{Provider: p}
+}
+
+// Providers 返回所有已注册的来源, 按名称排序
+func Providers() []Provider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	result := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result
+}
+
+// GetProvider 按名称获取来源
+func GetProvider(name string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrProviderNotFound, name)
+	}
+	return p, nil
+}
+
+// FindBuild 在 Builds 的结果中查找构建, 供 Resolve 的实现使用
+func FindBuild(ctx context.Context, p Provider, project, version, build string) (Build, error) {
+	if build == "" {
+		return Build{}, MCSTErrors.ErrBuildRequired
+	}
+	if _, ok := p.(*cachedProvider); !ok {
+		p = &cachedProvider{Provider: p}
+	}
+	builds, err := p.Builds(ctx, project, version)
+	if err != nil {
+		return Build{}, err
+	}
+	for _, b := range builds {
+		if b.ID == build {
+			return b, nil
+		}
+	}
+	return Build{}, MCSTErrors.ErrCoreNotFound
+}
+
+// BuildsPage 返回从 offset 开始的 limit 个构建和构建的总数; 来源没有实现 [Pager] 时从 Builds 的结果中截取
+func BuildsPage(ctx context.Context, p Provider, project, version string, offset, limit int) ([]Build, int, error) {
+	inner := p
+	if c, ok := p.(*cachedProvider); ok {
+		inner = c.Provider
+	}
+	if pager, ok := inner.(Pager); ok {
+		return pager.BuildsPage(ctx, project, version, offset, limit)
+	}
+	builds, err := p.Builds(ctx, project, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	builds = SortBuilds(builds)
+	offset = min(offset, len(builds))
+	return builds[offset:min(offset+limit, len(builds))], len(builds), nil
+}
+
+// SortBuilds 返回按更新时间从新到旧排列的副本, 没有更新时间的构建保持原来的顺序
+func SortBuilds(builds []Build) []Build {
+	sorted := slices.Clone(builds)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.After(sorted[j].Time) })
+	return sorted
+}
+
+// SortVersions 返回按版本号从新到旧排列的副本, 例如 1.20.1, 1.10, 1.9
+func SortVersions(versions []Version) []Version {
+	sorted := slices.Clone(versions)
+	sort.SliceStable(sorted, func(i, j int) bool { return CompareVersions(sorted[i].ID, sorted[j].ID) > 0 })
+	return sorted
+}
+
+// fetch 请求 url 并返回响应的内容, 不使用缓存
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := requests.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := requests.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s", MCSTErrors.ErrBadStatus, url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// getJSON 请求 url 并把响应解析到 v 中, name 为来源的名称, 用于缓存
+func getJSON(ctx context.Context, name, url string, v any) error {
+	body, err := get(ctx, name, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+var releaseVersion = regexp.MustCompile(`^\d+(\.\d+)+$`)
+
+// isReleaseVersion 判断是否为正式版, 例如 1.20.1; 快照(24w14a)和预览版(1.21-pre1)不是正式版
+func isReleaseVersion(version string) bool {
+	return releaseVersion.MatchString(version)
+}
+
+// listCache 缓存同一次运行中的列表结果, 避免解析和下载时重复请求
+var listCache sync.Map
+
+// cachedProvider 为来源加上 listCache
+type cachedProvider struct {
+	Provider
+}
+
+func (c *cachedProvider) Aliases() []string {
+	if aliaser, ok := c.Provider.(Aliaser); ok {
+		return aliaser.Aliases()
+	}
+	return nil
+}
+
+func (c *cachedProvider) Projects(ctx context.Context) ([]Project, error) {
+	return cached(c.Name()+"/projects", func() ([]Project, error) {
+		return c.Provider.Projects(ctx)
+	})
+}
+
+func (c *cachedProvider) Versions(ctx context.Context, project string) ([]Version, error) {
+	return cached(c.Name()+"/versions/"+project, func() ([]Version, error) {
+		return c.Provider.Versions(ctx, project)
+	})
+}
+
+func (c *cachedProvider) Builds(ctx context.Context, project, version string) ([]Build, error) {
+	return cached(c.Name()+"/builds/"+project+"/"+version, func() ([]Build, error) {
+		return c.Provider.Builds(ctx, project, version)
+	})
+}
+
+func cached[T any](key string, load func() (T, error)) (T, error) {
+	if value, ok := listCache.Load(key); ok {
+		return value.(T), nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	listCache.Store(key, value)
+	return value, nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (*countingProvider) Name() string { return "counting" }
+
+func (*countingProvider) Projects(context.Context) ([]api.Project, error) { return nil, nil }
+
+func (*countingProvider) Versions(context.Context, string) ([]api.Version, error) { return nil, nil }
+
+func (p *countingProvider) Builds(context.Context, string, string) ([]api.Build, error) {
+	p.calls++
+	return []api.Build{{ID: "1", SHA1: "abc"}}, nil
+}
+
+func (p *countingProvider) Resolve(ctx context.Context, project, version, build string) (api.Artifact, error) {
+	b, err := api.FindBuild(ctx, p, project, version, build)
+	if err != nil {
+		return api.Artifact{}, err
+	}
+	return api.Artifact{URL: "https://example.com/" + b.ID, SHA1: b.SHA1}, nil
+}
+
+func TestProviderRegistry(t *testing.T) {
+	counting := &countingProvider{}
+	api.Register(counting)
+	provider, err := api.GetProvider("counting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = provider.Builds(context.Background(), "core", ""); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := provider.Resolve(context.Background(), "core", "", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.SHA1 != "abc" {
+		t.Fatalf("SHA1 = %q", artifact.SHA1)
+	}
+	if counting.calls != 1 {
+		t.Fatalf("Builds 被调用了 %d 次, 应该使用缓存", counting.calls)
+	}
+	if _, err = provider.Resolve(context.Background(), "core", "", "2"); !errors.Is(err, MCSTErrors.ErrCoreNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err = api.GetProvider("missing"); !errors.Is(err, MCSTErrors.ErrProviderNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// 版本和构建版本可以使用的特殊值
+const (
+	Latest       = "latest"        // 最新的版本, 包括快照和测试版
+	LatestStable = "latest-stable" // 最新的稳定版
+)
+
+// candidate 可以比较新旧的版本或构建
+type candidate struct {
+	id     string
+	stable bool
+	build  Build // 只有构建有更新时间
+}
+
+// IsSpec 判断是否需要通过列表解析, 例如 latest, latest-stable, 1.20.x
+func IsSpec(spec string) bool {
+	return spec == Latest || spec == LatestStable || isPattern(spec)
+}
+
+// ResolveVersion 把 latest, latest-stable 或 1.20.x 这样的Minecraft版本解析为具体的版本, 其他值原样返回
+func ResolveVersion(ctx context.Context, p Provider, project, spec string) (string, error) {
+	if !IsSpec(spec) {
+		return spec, nil
+	}
+	versions, err := p.Versions(ctx, project)
+	if err != nil {
+		return "", err
+	}
+	candidates := make([]candidate, 0, len(versions))
+	for _, v := range versions {
+		candidates = append(candidates, candidate{id: v.ID, stable: v.Stable, build: Build{Time: v.Time}})
+	}
+	return resolve(candidates, spec)
+}
+
+// ResolveBuild 与 [ResolveVersion] 相同, 解析的是构建版本
+func ResolveBuild(ctx context.Context, p Provider, project, version, spec string) (string, error) {
+	if !IsSpec(spec) {
+		return spec, nil
+	}
+	builds, err := p.Builds(ctx, project, version)
+	if err != nil {
+		return "", err
+	}
+	candidates := make([]candidate, 0, len(builds))
+	for _, b := range builds {
+		candidates = append(candidates, candidate{id: b.ID, stable: b.Stable, build: b})
+	}
+	return resolve(candidates, spec)
+}
+
+// resolve 从符合 spec 的候选中选择最新的一个; 使用版本号范围时优先选择稳定版
+func resolve(candidates []candidate, spec string) (string, error) {
+	var matched []candidate
+	for _, c := range candidates {
+		switch {
+		case spec == Latest,
+			spec == LatestStable && c.stable,
+			isPattern(spec) && matchPattern(spec, c.id):
+			matched = append(matched, c)
+		}
+	}
+	if isPattern(spec) {
+		var stable []candidate
+		for _, c := range matched {
+			if c.stable {
+				stable = append(stable, c)
+			}
+		}
+		if len(stable) > 0 {
+			matched = stable
+		}
+	}
+	if len(matched) == 0 {
+		return "", fmt.Errorf("%w: %s", MCSTErrors.ErrNoMatchingVersion, spec)
+	}
+	newest := matched[0]
+	for _, c := range matched[1:] {
+		if newer(c, newest) {
+			newest = c
+		}
+	}
+	return newest.id, nil
+}
+
+// newer 有更新时间时比较更新时间, 否则比较版本号
+func newer(a, b candidate) bool {
+	if !a.build.Time.IsZero() && !b.build.Time.IsZero() {
+		return a.build.Time.After(b.build.Time)
+	}
+	return CompareVersions(a.id, b.id) > 0
+}
+
+// isPattern 判断是否为 1.20.x 或 1.20.* 这样的版本号范围
+func isPattern(spec string) bool {
+	for _, part := range strings.Split(spec, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern 逐段比较版本号, x 可以匹配任意一段或多段; 1.20.x 匹配 1.20 和 1.20.4, 不匹配 1.20.4-pre1
+func matchPattern(pattern, version string) bool {
+	patternParts := strings.Split(pattern, ".")
+	versionParts := strings.Split(version, ".")
+	for i, part := range patternParts {
+		if part == "x" || part == "X" || part == "*" {
+			for _, rest := range versionParts[min(i, len(versionParts)):] {
+				if !isNumber(rest) {
+					return false
+				}
+			}
+			return true
+		}
+		if i >= len(versionParts) || versionParts[i] != part {
+			return false
+		}
+	}
+	return len(patternParts) == len(versionParts)
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareVersions 按自然顺序比较版本号, 数字部分按数值比较; 例如 1.9 < 1.10, build99 < build100
+func CompareVersions(a, b string) int {
+	for a != "" && b != "" {
+		var partA, partB string
+		partA, a = nextPart(a)
+		partB, b = nextPart(b)
+		if partA == partB {
+			continue
+		}
+		numA, errA := strconv.Atoi(partA)
+		numB, errB := strconv.Atoi(partB)
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				return compare(numA, numB)
+			}
+		case errA == nil:
+			// 1.20 比 1.20-pre1 新
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			return strings.Compare(partA, partB)
+		}
+	}
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		// 1.20 比 1.20.1 旧, 但比 1.20-pre1 新
+		if b[0] == '.' || unicode.IsDigit(rune(b[0])) {
+			return -1
+		}
+		return 1
+	default:
+		return -CompareVersions(b, a)
+	}
+}
+
+// nextPart 返回开头连续的数字或非数字部分
+func nextPart(s string) (string, string) {
+	digit := unicode.IsDigit(rune(s[0]))
+	for i, r := range s {
+		if unicode.IsDigit(r) != digit {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+func compare(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.9", "1.10", -1},
+		{"1.20", "1.20.1", -1},
+		{"1.20", "1.20-pre1", 1},
+		{"1.20.1", "1.20-pre1", 1},
+		{"build99", "build100", -1},
+		{"build", "build1", -1},
+		{"47.1.0", "47.1.0", 0},
+	}
+	for _, test := range tests {
+		if got := api.CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := api.CompareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := api.SortVersions([]api.Version{{ID: "1.9"}, {ID: "1.20.1"}, {ID: "1.10"}, {ID: "1.20-pre1"}})
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, v.ID)
+	}
+	if want := []string{"1.20.1", "1.20-pre1", "1.10", "1.9"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("SortVersions() = %v, want %v", ids, want)
+	}
+}
+
+type staticProvider struct {
+	versions []api.Version
+	builds   []api.Build
+}
+
+func (staticProvider) Name() string { return "static" }
+
+func (staticProvider) Projects(context.Context) ([]api.Project, error) { return nil, nil }
+
+func (p staticProvider) Versions(context.Context, string) ([]api.Version, error) {
+	return p.versions, nil
+}
+
+func (p staticProvider) Builds(context.Context, string, string) ([]api.Build, error) {
+	return p.builds, nil
+}
+
+func (staticProvider) Resolve(context.Context, string, string, string) (api.Artifact, error) {
+	return api.Artifact{}, nil
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Now()
+	provider := staticProvider{
+		versions: []api.Version{
+			{ID: "1.19.4", Stable: true},
+			{ID: "1.20", Stable: true},
+			{ID: "1.20.4", Stable: true},
+			{ID: "1.20.10-rc1"},
+			{ID: "1.21-pre1"},
+		},
+		builds: []api.Build{
+			{ID: "build10", Stable: true, Time: now.Add(-2 * time.Hour)},
+			{ID: "build9", Stable: true, Time: now.Add(-time.Hour)}, // 重新构建过, 更新时间更新
+			{ID: "build11", Time: now.Add(-3 * time.Hour)},
+		},
+	}
+	versionTests := map[string]string{
+		api.Latest:       "1.21-pre1",
+		api.LatestStable: "1.20.4",
+		"1.20.x":         "1.20.4",
+		"1.19.*":         "1.19.4",
+		"1.20.1":         "1.20.1", // 具体的版本不检查
+	}
+	for spec, want := range versionTests {
+		got, err := api.ResolveVersion(context.Background(), provider, "", spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("ResolveVersion(%q) = %q, want %q", spec, got, want)
+		}
+	}
+	if _, err := api.ResolveVersion(context.Background(), provider, "", "1.18.x"); !errors.Is(err, MCSTErrors.ErrNoMatchingVersion) {
+		t.Errorf("err = %v", err)
+	}
+
+	// 有更新时间时按更新时间选择
+	for spec, want := range map[string]string{api.Latest: "build9", api.LatestStable: "build9"} {
+		got, err := api.ResolveBuild(context.Background(), provider, "", "", spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("ResolveBuild(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ package configs
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
@@ -36,6 +37,30 @@ var (
 			MinSplitSize:           "5M",
 			Options:                []string{},
 		},
+		Segmented: Segmented{
+			Split:                  5,
+			MaxConnectionPerServer: 5,
+			MinSplitSize:           "5M",
+		},
+		Cache: Cache{
+			TTL:       "1h",
+			Providers: map[string]string{},
+		},
+		Network: Network{
+			ConnectTimeout: "10s",
+			Timeout:        "30s",
+			Retries:        3,
+			RetryWait:      "1s",
+			Mirrors: map[string][]string{
+				"https://piston-meta.mojang.com":       {"https://bmclapi2.bangbang93.com"},
+				"https://piston-data.mojang.com":       {"https://bmclapi2.bangbang93.com"},
+				"https://meta.fabricmc.net":            {"https://bmclapi2.bangbang93.com/fabric-meta"},
+				"https://maven.fabricmc.net":           {"https://bmclapi2.bangbang93.com/maven"},
+				"https://maven.minecraftforge.net":     {"https://bmclapi2.bangbang93.com/maven"},
+				"https://maven.neoforged.net/releases": {"https://bmclapi2.bangbang93.com/maven"},
+			},
+		},
+		Backend:        "native",
 		AutoAcceptEULA: false,
 		Language:       language.English.String(),
 	}
@@ -45,54 +70,179 @@ var (
 	rootDir      string
 	ServersDir   string
 	DownloadsDir string
+	StoreDir     string // 按 SHA-256 存放核心的仓库
+	CacheDir     string // 核心来源的元数据缓存
+	RunDir       string // 守护进程的套接字和服务器的运行状态
 	configsPath  string
 )
 
 type Core struct {
-	ID         int    `yaml:"id"`          // 核心id
-	URL        string `yaml:"url"`         // 下载地址(如果不是本地的话)
-	FileName   string `yaml:"file_name"`   // 文件名
-	FilePath   string `yaml:"file_path"`   // 文件路径
-	ExtrasData any    `yaml:"extras_data"` // 其他数据
+	ID               int        `yaml:"id" json:"id"`                                           // 核心id
+	Label            string     `yaml:"label" json:"label"`                                     // 名称, 方便记忆
+	Provider         string     `yaml:"provider" json:"provider"`                               // 来源: local, remote, fastmirror, polars...
+	Project          string     `yaml:"project,omitempty" json:"project,omitempty"`             // 来源中的核心, 例如 paper
+	MinecraftVersion string     `yaml:"mc_version,omitempty" json:"mc_version,omitempty"`       // Minecraft版本
+	Build            string     `yaml:"build_version,omitempty" json:"build_version,omitempty"` // 构建版本, 为空时由来源选择
+	URL              string     `yaml:"url" json:"url"`                                         // 下载地址(如果不是本地的话)
+	FileName         string     `yaml:"file_name" json:"file_name"`                             // 文件名
+	FilePath         string     `yaml:"file_path" json:"file_path"`                             // 文件路径
+	SHA1             string     `yaml:"sha1" json:"sha1"`                                       // 文件的SHA-1
+	SHA256           string     `yaml:"sha256" json:"sha256"`                                   // 文件的SHA-256
+	JavaMajor        int        `yaml:"java_major,omitempty" json:"java_major,omitempty"`       // 需要的Java主版本, 0为未知
+	Installer        *Installer `yaml:"installer,omitempty" json:"installer,omitempty"`         // 不为空时核心是安装器而不是服务端
+	ExtrasData       any        `yaml:"extras_data" json:"extras_data"`                         // 其他数据
+}
+
+// Installer 创建服务器时在服务器目录中运行 'java -jar <核心> Args...'
+//
+// 安装器生成了 run.sh(Windows为 run.bat) 时使用其中的参数文件启动服务器, 否则使用匹配 Jar 的文件
+type Installer struct {
+	Args []string `yaml:"args" json:"args"` // 安装器参数
+	Jar  string   `yaml:"jar" json:"jar"`   // 安装后用于启动服务器的jar, 可以使用通配符
+}
+
+// Extras 把 ExtrasData 解析到 out 中; 从配置文件读取的 ExtrasData 是 map, 需要通过此函数转换为来源定义的类型
+func (c Core) Extras(out any) error {
+	return decodeExtras(c.ExtrasData, out)
 }
 
 type Java struct {
-	Path      string   `yaml:"path"`       // Java路径
-	Args      []string `yaml:"args"`       // Java虚拟机参数
-	MaxMemory uint64   `yaml:"max_memory"` // Java虚拟机最大堆内存
-	MinMemory uint64   `yaml:"min_memory"` // Java虚拟机初始堆内存
-	Encoding  string   `yaml:"encoding"`   // 编码
+	Path      string   `yaml:"path" json:"path"`             // Java路径
+	Args      []string `yaml:"args" json:"args"`             // Java虚拟机参数
+	MaxMemory uint64   `yaml:"max_memory" json:"max_memory"` // Java虚拟机最大堆内存
+	MinMemory uint64   `yaml:"min_memory" json:"min_memory"` // Java虚拟机初始堆内存
+	Encoding  string   `yaml:"encoding" json:"encoding"`     // 编码
+}
+
+// ServerCore 记录服务器使用的核心; 即使核心之后被删除, 这些信息也会保留
+type ServerCore struct {
+	ID         int    `yaml:"id" json:"id"`                   // 核心id
+	SHA1       string `yaml:"sha1" json:"sha1"`               // 文件的SHA-1
+	SHA256     string `yaml:"sha256" json:"sha256"`           // 文件的SHA-256
+	Provider   string `yaml:"provider" json:"provider"`       // 核心的来源
+	ExtrasData any    `yaml:"extras_data" json:"extras_data"` // 核心的其他数据
 }
+
+// NewServerCore 从核心生成服务器中保存的核心信息
+func NewServerCore(core Core) *ServerCore {
+	return &ServerCore{
+		ID:         core.ID,
+		SHA1:       core.SHA1,
+		SHA256:     core.SHA256,
+		Provider:   core.Provider,
+		ExtrasData: core.ExtrasData,
+	}
+}
+
+// Extras 与 [Core.Extras] 相同
+func (c ServerCore) Extras(out any) error {
+	return decodeExtras(c.ExtrasData, out)
+}
+
+func decodeExtras(extras, out any) error {
+	data, err := yaml.Marshal(extras)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
+
 type Server struct {
-	Name       string   `yaml:"name"`        // 服务器名称
-	Java       Java     `yaml:"java"`        // Java
-	ServerArgs []string `yaml:"server_args"` // Minecraft服务器参数
+	Name       string      `yaml:"name" json:"name"`                                       // 服务器名称
+	Java       Java        `yaml:"java" json:"java"`                                       // Java
+	ServerArgs []string    `yaml:"server_args" json:"server_args"`                         // Minecraft服务器参数
+	Launch     Launch      `yaml:"launch" json:"launch"`                                   // 启动方式
+	Shutdown   Shutdown    `yaml:"shutdown" json:"shutdown"`                               // 停止方式
+	Restart    Restart     `yaml:"restart" json:"restart"`                                 // 自动重启的策略
+	Core       *ServerCore `yaml:"core,omitempty" json:"core,omitempty"`                   // 当前使用的核心, 旧版本创建的服务器为nil
+	Previous   *ServerCore `yaml:"previous_core,omitempty" json:"previous_core,omitempty"` // 升级前使用的核心, 用于回滚
 }
 
 type IDM struct {
-	RootDir string `yaml:"root_dir"`
+	RootDir string `yaml:"root_dir" json:"root_dir"`
+}
+
+// External 外部下载程序, Args 中的 {url} {dir} {file} {path} 会被替换
+type External struct {
+	Command string   `yaml:"command" json:"command"`
+	Args    []string `yaml:"args" json:"args"`
 }
 
 type Aria2 struct {
-	Enable                 bool     `yaml:"enable"`
-	RetryWait              int      `yaml:"retry_wait"`
-	Split                  int      `yaml:"split"`
-	MaxConnectionPerServer int      `yaml:"max_connection_per_server"`
-	MinSplitSize           string   `yaml:"min_split_size"`
-	Options                []string `yaml:"options"`
+	Enable bool `yaml:"enable,omitempty" json:"enable,omitempty"` // 已弃用, 请使用 Settings.Backend
+
+	RetryWait              int      `yaml:"retry_wait" json:"retry_wait"`
+	Split                  int      `yaml:"split" json:"split"`
+	MaxConnectionPerServer int      `yaml:"max_connection_per_server" json:"max_connection_per_server"`
+	MinSplitSize           string   `yaml:"min_split_size" json:"min_split_size"`
+	Options                []string `yaml:"options" json:"options"`       // 启动 aria2c 时额外的命令行参数
+	RPCURL                 string   `yaml:"rpc_url" json:"rpc_url"`       // 本机已有的 aria2 的 JSON-RPC 地址, 为空时自动启动 aria2c
+	RPCSecret              string   `yaml:"rpc_secret" json:"rpc_secret"` // 已有的 aria2 的 --rpc-secret
+}
+
+// Segmented 内置的多线程分段下载, 参数的含义与 aria2 相同
+type Segmented struct {
+	Split                  int    `yaml:"split" json:"split"`
+	MaxConnectionPerServer int    `yaml:"max_connection_per_server" json:"max_connection_per_server"`
+	MinSplitSize           string `yaml:"min_split_size" json:"min_split_size"`
+}
+
+// Cache 核心来源元数据的缓存时间, 使用 [time.ParseDuration] 的格式, 例如 30m, 6h
+type Cache struct {
+	TTL       string            `yaml:"ttl" json:"ttl"`             // 默认的缓存时间
+	Providers map[string]string `yaml:"providers" json:"providers"` // 按来源名称设置的缓存时间
+}
+
+// TTLFor 返回来源的缓存时间, 设置无效时使用默认值
+func (c Cache) TTLFor(provider string) time.Duration {
+	return firstDuration(c.Providers[provider], c.TTL, DefaultSettings.Cache.TTL)
+}
+
+// Network 网络请求的设置, 时间使用 [time.ParseDuration] 的格式
+type Network struct {
+	ConnectTimeout string              `yaml:"connect_timeout" json:"connect_timeout"` // 建立连接(包括TLS握手)的超时时间
+	Timeout        string              `yaml:"timeout" json:"timeout"`                 // 请求的整体超时时间; 下载文件时限制等待响应和每次读取数据的时间
+	Retries        int                 `yaml:"retries" json:"retries"`                 // 网络错误或服务器返回 5xx 时的重试次数
+	RetryWait      string              `yaml:"retry_wait" json:"retry_wait"`           // 第一次重试前等待的时间, 之后每次翻倍
+	Proxy          string              `yaml:"proxy" json:"proxy"`                     // 代理地址, 支持 http, https, socks5; 为空时使用 HTTP_PROXY 等环境变量
+	CABundle       string              `yaml:"ca_bundle" json:"ca_bundle"`             // 额外信任的PEM格式的CA证书文件
+	Mirrors        map[string][]string `yaml:"mirrors" json:"mirrors"`                 // 地址前缀 -> 按顺序尝试的镜像地址前缀
+}
+
+// Timeouts 返回连接超时, 整体超时和第一次重试前等待的时间, 设置无效时使用默认值
+func (n Network) Timeouts() (connect, overall, retryWait time.Duration) {
+	return firstDuration(n.ConnectTimeout, DefaultSettings.Network.ConnectTimeout),
+		firstDuration(n.Timeout, DefaultSettings.Network.Timeout),
+		firstDuration(n.RetryWait, DefaultSettings.Network.RetryWait)
+}
+
+// firstDuration 返回第一个有效的时间, 都无效时返回0
+func firstDuration(values ...string) time.Duration {
+	for _, value := range values {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return 0
 }
 
 type Settings struct {
-	Aria2          Aria2  `yaml:"aria2"`
-	IDM            IDM    `yaml:"idm"`
-	AutoAcceptEULA bool   `yaml:"auto_accept_eula"`
-	Language       string `yaml:"language"`
+	Backend        string    `yaml:"backend" json:"backend"` // 下载后端: native, segmented, aria2, idm, external 或其他已注册的后端
+	Cache          Cache     `yaml:"cache" json:"cache"`
+	Network        Network   `yaml:"network" json:"network"`
+	Aria2          Aria2     `yaml:"aria2" json:"aria2"`
+	Segmented      Segmented `yaml:"segmented" json:"segmented"`
+	IDM            IDM       `yaml:"idm" json:"idm"`
+	External       External  `yaml:"external" json:"external"`
+	AutoAcceptEULA bool      `yaml:"auto_accept_eula" json:"auto_accept_eula"`
+	Language       string    `yaml:"language" json:"language"`
 }
 
 type Config struct {
-	Cores    map[int]Core      `yaml:"cores"`   // 核心列表
-	Servers  map[string]Server `yaml:"servers"` // 服务器列表, 如果服务器名称(key)为temp, CreatePage调用时会视为暂存配置而不是名为temp的服务器
-	Settings Settings          `yaml:"settings"`
+	NextCoreID int               `yaml:"next_core_id" json:"next_core_id"` // 下一个核心的id, 只增不减, 删除核心后id也不会被复用
+	Cores      map[int]Core      `yaml:"cores" json:"cores"`               // 核心列表
+	Servers    map[string]Server `yaml:"servers" json:"servers"`           // 服务器列表, 如果服务器名称(key)为temp, CreatePage调用时会视为暂存配置而不是名为temp的服务器
+	Settings   Settings          `yaml:"settings" json:"settings"`
 }
 
 func InitData() error {
@@ -103,6 +253,9 @@ func InitData() error {
 	rootDir = filepath.Join(UserHomeDir, ".config", "MCST")
 	ServersDir = filepath.Join(rootDir, "servers")
 	DownloadsDir = filepath.Join(rootDir, "downloads")
+	StoreDir = filepath.Join(rootDir, "store")
+	CacheDir = filepath.Join(rootDir, "cache")
+	RunDir = filepath.Join(rootDir, "run")
 	configsPath = filepath.Join(rootDir, "configs.yaml")
 
 	if err = os.MkdirAll(rootDir, 0o755); err != nil {
@@ -114,6 +267,16 @@ func InitData() error {
 	if err = os.MkdirAll(DownloadsDir, 0o755); err != nil {
 		return err
 	}
+	if err = os.MkdirAll(StoreDir, 0o755); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(CacheDir, 0o755); err != nil {
+		return err
+	}
+	// 只有当前用户可以连接守护进程
+	if err = os.MkdirAll(RunDir, 0o700); err != nil {
+		return err
+	}
 
 	// 初始化
 
@@ -138,9 +301,58 @@ func InitData() error {
 	if err = yaml.Unmarshal(file, &Configs); err != nil {
 		return err
 	}
+	Configs.migrate()
 	return nil
 }
 
+// migrate 补全旧版本配置文件中缺少的字段
+func (c *Config) migrate() {
+	if c.Cores == nil {
+		c.Cores = map[int]Core{}
+	}
+	if c.Servers == nil {
+		c.Servers = map[string]Server{}
+	}
+	// 旧版本使用核心数量作为id
+	for id := range c.Cores {
+		if id >= c.NextCoreID {
+			c.NextCoreID = id + 1
+		}
+	}
+	if c.Settings.Segmented == (Segmented{}) {
+		c.Settings.Segmented = DefaultSettings.Segmented
+	}
+	if c.Settings.Backend == "" {
+		c.Settings.Backend = DefaultSettings.Backend
+		if c.Settings.Aria2.Enable {
+			c.Settings.Backend = "aria2"
+		}
+	}
+	c.Settings.Aria2.Enable = false
+	if c.Settings.Cache.TTL == "" {
+		c.Settings.Cache.TTL = DefaultSettings.Cache.TTL
+	}
+	// 旧版本没有网络设置
+	if network := c.Settings.Network; network.ConnectTimeout == "" && network.Timeout == "" && network.RetryWait == "" && network.Mirrors == nil {
+		c.Settings.Network = DefaultSettings.Network
+	}
+	// 旧版本总是使用 -jar server.jar 启动
+	for name, server := range c.Servers {
+		if server.Launch.Mode == "" {
+			server.Launch = DefaultLaunch
+			c.Servers[name] = server
+		}
+	}
+}
+
+// AddCore 为核心分配一个新的id并添加到核心列表
+func (c *Config) AddCore(core Core) Core {
+	core.ID = c.NextCoreID
+	c.NextCoreID++
+	c.Cores[core.ID] = core
+	return core
+}
+
 func (c *Config) Save() error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
@@ -0,0 +1,127 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package configs_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// initWith 使用临时的用户目录和指定的配置文件初始化
+func initWith(t *testing.T, data string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "MCST")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "configs.yaml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configs.Configs = configs.Config{}
+	if err := configs.InitData(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateCoreIDs(t *testing.T) {
+	initWith(t, `
+cores:
+  0: {id: 0, file_name: a.jar}
+  1: {id: 1, file_name: b.jar}
+settings:
+  aria2: {enable: true}
+`)
+	if configs.Configs.NextCoreID != 2 {
+		t.Fatalf("next_core_id 应该是2, 实际是 %d", configs.Configs.NextCoreID)
+	}
+	delete(configs.Configs.Cores, 1)
+	if core := configs.Configs.AddCore(configs.Core{}); core.ID != 2 {
+		t.Fatalf("删除核心后id被复用: %d", core.ID)
+	}
+	if configs.Configs.Settings.Backend != "aria2" {
+		t.Errorf("aria2.enable 没有迁移到 backend: %q", configs.Configs.Settings.Backend)
+	}
+}
+
+func TestMigrateLaunch(t *testing.T) {
+	initWith(t, `
+servers:
+  old: {name: old}
+  forge: {name: forge, launch: {mode: arg_file, arg_files: [user_jvm_args.txt]}}
+`)
+	want := map[string]configs.Launch{
+		"old":   configs.DefaultLaunch,
+		"forge": {Mode: configs.LaunchArgFile, ArgFiles: []string{"user_jvm_args.txt"}},
+	}
+	for name, launch := range want {
+		if server := configs.Configs.Servers[name]; !reflect.DeepEqual(server.Launch, launch) {
+			t.Errorf("%s: launch = %+v, want %+v", name, server.Launch, launch)
+		}
+	}
+}
+
+func TestServerCommand(t *testing.T) {
+	server := configs.Server{
+		Java:       configs.Java{Path: "java", MinMemory: 1024, MaxMemory: 2048, Encoding: "UTF-8", Args: []string{"-Dfoo"}},
+		ServerArgs: []string{"--nogui"},
+	}
+	tests := []struct {
+		launch configs.Launch
+		name   string
+		args   []string
+	}{
+		{configs.DefaultLaunch, "java", []string{"-Xms1024", "-Xmx2048", "-Dfile.encoding=UTF-8", "-Dfoo", "-jar", "server.jar", "--nogui"}},
+		{
+			configs.Launch{Mode: configs.LaunchMainClass, MainClass: "net.minecraft.server.Main", Classpath: []string{"a.jar", "b.jar"}},
+			"java", []string{"-Xms1024", "-Xmx2048", "-Dfile.encoding=UTF-8", "-Dfoo", "-cp", "a.jar" + string(os.PathListSeparator) + "b.jar", "net.minecraft.server.Main", "--nogui"},
+		},
+		{
+			configs.Launch{Mode: configs.LaunchArgFile, ArgFiles: []string{"user_jvm_args.txt", "unix_args.txt"}},
+			"java", []string{"-Xms1024", "-Xmx2048", "-Dfile.encoding=UTF-8", "-Dfoo", "@user_jvm_args.txt", "@unix_args.txt", "--nogui"},
+		},
+		{configs.Launch{Mode: configs.LaunchExecutable, Executable: "./bedrock_server"}, "./bedrock_server", []string{"--nogui"}},
+	}
+	for _, test := range tests {
+		server.Launch = test.launch
+		name, args, err := server.Command()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != test.name || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%s: Command() = %s %v", test.launch.Mode, name, args)
+		}
+	}
+
+	server.Launch = configs.Launch{Mode: "unknown"}
+	if _, _, err := server.Command(); !errors.Is(err, MCSTErrors.ErrUnknownLaunchMode) {
+		t.Errorf("err = %v", err)
+	}
+	server.Launch = configs.Launch{Mode: configs.LaunchArgFile}
+	if _, _, err := server.Command(); !errors.Is(err, MCSTErrors.ErrLaunchIncomplete) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package configs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// 启动方式
+const (
+	LaunchJar        = "jar"        // java -jar <Jar>
+	LaunchMainClass  = "main_class" // java -cp <Classpath> <MainClass>
+	LaunchArgFile    = "arg_file"   // java @<ArgFiles>..., 例如 Forge 生成的参数文件
+	LaunchExecutable = "executable" // 直接运行 Executable, 不使用 Java 的设置, 例如 Bedrock 服务器或自定义的启动脚本
+)
+
+// LaunchModes 所有的启动方式
+var LaunchModes = []string{LaunchJar, LaunchMainClass, LaunchArgFile, LaunchExecutable}
+
+// ServerJar 服务器核心在服务器目录中的文件名
+const ServerJar = "server.jar"
+
+// DefaultLaunch 新建服务器和旧版本配置的启动方式
+var DefaultLaunch = Launch{Mode: LaunchJar, Jar: ServerJar}
+
+// Launch 服务器的启动方式; 路径都相对于服务器目录
+type Launch struct {
+	Mode       string   `yaml:"mode" json:"mode"`                                 // jar, main_class, arg_file 或 executable
+	Jar        string   `yaml:"jar,omitempty" json:"jar,omitempty"`               // jar 模式使用的文件
+	MainClass  string   `yaml:"main_class,omitempty" json:"main_class,omitempty"` // main_class 模式的主类
+	Classpath  []string `yaml:"classpath,omitempty" json:"classpath,omitempty"`   // main_class 模式的类路径
+	ArgFiles   []string `yaml:"arg_files,omitempty" json:"arg_files,omitempty"`   // arg_file 模式的Java参数文件
+	Executable string   `yaml:"executable,omitempty" json:"executable,omitempty"` // executable 模式运行的程序, 服务器目录中的程序需要以 ./ 开头
+}
+
+// DefaultShutdown 没有设置停止方式时使用的值
+var DefaultShutdown = Shutdown{Command: "stop", Timeout: "60s", KillTimeout: "10s"}
+
+// Shutdown 服务器的停止方式, 时间使用 [time.ParseDuration] 的格式
+type Shutdown struct {
+	Command     string `yaml:"command,omitempty" json:"command,omitempty"`           // 在控制台输入的停止命令
+	Timeout     string `yaml:"timeout,omitempty" json:"timeout,omitempty"`           // 等待服务器保存并退出的时间, 超时后发送 SIGTERM
+	KillTimeout string `yaml:"kill_timeout,omitempty" json:"kill_timeout,omitempty"` // 发送 SIGTERM 后等待的时间, 超时后发送 SIGKILL
+}
+
+// StopCommand 返回停止命令, 未设置时为 stop
+func (s Shutdown) StopCommand() string {
+	if s.Command == "" {
+		return DefaultShutdown.Command
+	}
+	return s.Command
+}
+
+// Timeouts 返回停止服务器的等待时间, 未设置或无效时使用 [DefaultShutdown]
+func (s Shutdown) Timeouts() (timeout, killTimeout time.Duration) {
+	return firstDuration(s.Timeout, DefaultShutdown.Timeout), firstDuration(s.KillTimeout, DefaultShutdown.KillTimeout)
+}
+
+// 自动重启的策略
+const (
+	RestartNever     = "never"      // 不自动重启
+	RestartOnFailure = "on-failure" // 退出代码不为 0 时重启
+	RestartAlways    = "always"     // 服务器自己退出后总是重启, 'MCST stop' 等主动停止的除外
+)
+
+// RestartPolicies 所有的自动重启策略
+var RestartPolicies = []string{RestartNever, RestartOnFailure, RestartAlways}
+
+// DefaultRestart 没有设置自动重启时使用的值
+var DefaultRestart = Restart{Policy: RestartNever, Backoff: "5s", MaxBackoff: "5m", MaxRestarts: 5, Window: "10m"}
+
+// Restart 服务器退出后自动重启的设置, 时间使用 [time.ParseDuration] 的格式
+type Restart struct {
+	Policy      string `yaml:"policy,omitempty" json:"policy,omitempty"`             // never, on-failure 或 always
+	Backoff     string `yaml:"backoff,omitempty" json:"backoff,omitempty"`           // 第一次重启前等待的时间, 之后每次翻倍
+	MaxBackoff  string `yaml:"max_backoff,omitempty" json:"max_backoff,omitempty"`   // 重启前等待的最长时间
+	MaxRestarts int    `yaml:"max_restarts,omitempty" json:"max_restarts,omitempty"` // Window 内最多重启的次数, 超过后认为服务器在反复崩溃, 不再重启
+	Window      string `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// ShouldRestart 判断服务器自己退出后是否需要重启, crashed 表示退出代码不为 0
+func (r Restart) ShouldRestart(crashed bool) bool {
+	switch r.Policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return crashed
+	default:
+		return false
+	}
+}
+
+// Limits 返回重启的等待时间和次数限制, 未设置或无效时使用 [DefaultRestart]
+func (r Restart) Limits() (backoff, maxBackoff, window time.Duration, maxRestarts int) {
+	maxRestarts = r.MaxRestarts
+	if maxRestarts <= 0 {
+		maxRestarts = DefaultRestart.MaxRestarts
+	}
+	return firstDuration(r.Backoff, DefaultRestart.Backoff),
+		firstDuration(r.MaxBackoff, DefaultRestart.MaxBackoff),
+		firstDuration(r.Window, DefaultRestart.Window),
+		maxRestarts
+}
+
+// Command 返回启动服务器的程序和参数, 应该在服务器目录中运行
+func (s Server) Command() (string, []string, error) {
+	launch := s.Launch
+	if launch.Mode == LaunchExecutable {
+		if launch.Executable == "" {
+			return "", nil, fmt.Errorf("%w: executable", MCSTErrors.ErrLaunchIncomplete)
+		}
+		return launch.Executable, s.ServerArgs, nil
+	}
+
+	args := []string{
+		fmt.Sprintf("-Xms%d", s.Java.MinMemory),
+		fmt.Sprintf("-Xmx%d", s.Java.MaxMemory),
+		fmt.Sprintf("-Dfile.encoding=%s", s.Java.Encoding),
+	}
+	args = append(args, s.Java.Args...)
+	switch launch.Mode {
+	case LaunchJar:
+		if launch.Jar == "" {
+			return "", nil, fmt.Errorf("%w: jar", MCSTErrors.ErrLaunchIncomplete)
+		}
+		args = append(args, "-jar", launch.Jar)
+	case LaunchMainClass:
+		if launch.MainClass == "" {
+			return "", nil, fmt.Errorf("%w: main_class", MCSTErrors.ErrLaunchIncomplete)
+		}
+		if len(launch.Classpath) > 0 {
+			args = append(args, "-cp", strings.Join(launch.Classpath, string(os.PathListSeparator)))
+		}
+		args = append(args, launch.MainClass)
+	case LaunchArgFile:
+		if len(launch.ArgFiles) == 0 {
+			return "", nil, fmt.Errorf("%w: arg_files", MCSTErrors.ErrLaunchIncomplete)
+		}
+		for _, argFile := range launch.ArgFiles {
+			args = append(args, "@"+argFile)
+		}
+	default:
+		return "", nil, fmt.Errorf("%w: %q", MCSTErrors.ErrUnknownLaunchMode, launch.Mode)
+	}
+	args = append(args, s.ServerArgs...)
+	return s.Java.Path, args, nil
+}
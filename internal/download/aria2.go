@@ -19,38 +19,155 @@
 package download
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Arama0517/MCST/internal/build"
+	"github.com/Arama0517/MCST/internal/bytes"
 	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/requests"
 	"github.com/go-cmd/cmd"
 	"github.com/siku2/arigo"
 )
 
-const StatusComplete arigo.DownloadStatus = "complete"
+const (
+	BackendAria2 = "aria2"
+
+	StatusComplete arigo.DownloadStatus = "complete"
+)
+
+func init() {
+	RegisterBackend(BackendAria2, func() Backend { return &aria2Backend{} })
+}
+
+// aria2Ready 等待本地启动的 aria2c 开放 JSON-RPC 的最长时间
+const aria2ReadyTimeout = 10 * time.Second
+
+// aria2Backend 通过 JSON-RPC 使用 aria2 下载; 设置了 rpc_url 时连接已有的 aria2, 否则启动一个 aria2c
+type aria2Backend struct {
+	progress
+	*result
+	task     *Task
+	aria2Cmd *cmd.Cmd
+	gid      arigo.GID
+	canceled atomic.Bool
+}
+
+func (b *aria2Backend) Start(ctx context.Context, task *Task) error {
+	// aria2 会自己发起请求
+	_ = task.Response.Body.Close()
+	b.task = task
+	b.total.Store(-1)
+	b.result = newResult()
+	settings := configs.Configs.Settings.Aria2
+
+	var client *arigo.Client
+	if settings.RPCURL != "" {
+		if err := CheckAria2RPCURL(settings.RPCURL); err != nil {
+			return err
+		}
+		c, err := arigo.Dial(aria2WebSocketURL(settings.RPCURL), settings.RPCSecret)
+		if err != nil {
+			return err
+		}
+		client = &c
+	} else {
+		c, err := b.spawn(ctx, filepath.Dir(task.FilePath))
+		if err != nil {
+			return err
+		}
+		client = c
+	}
+
+	// 获取GID
+	options := &arigo.Options{
+		Dir:                    filepath.Dir(task.FilePath),
+		Out:                    filepath.Base(task.FilePath),
+		AllowOverwrite:         true,
+		Continue:               true,
+		RetryWait:              uint(settings.RetryWait),
+		Split:                  uint(settings.Split),
+		MaxConnectionPerServer: uint(settings.MaxConnectionPerServer),
+	}
+	if minSplitSize, err := bytes.ToBytes(settings.MinSplitSize); err == nil {
+		options.MinSplitSize = uint(minSplitSize)
+	}
+	// aria2 只支持 HTTP 代理
+	if proxy := configs.Configs.Settings.Network.Proxy; strings.HasPrefix(proxy, "http://") || strings.HasPrefix(proxy, "https://") {
+		options.AllProxy = proxy
+	}
+	var err error
+	b.gid, err = client.AddURI(arigo.URIs(requests.MirrorURLs(task.URL)...), options)
+	if err != nil {
+		_ = client.Close()
+		b.stop()
+		return err
+	}
+	go func() {
+		path, err := b.poll(ctx)
+		_ = client.Close()
+		b.stop()
+		if b.canceled.Load() {
+			b.removePartial()
+		}
+		b.finish(path, err)
+	}()
+	return nil
+}
+
+// spawn 启动一个只监听本地回环地址的 aria2c, 使用随机的端口和密钥; ctx 取消后停止 aria2c
+func (b *aria2Backend) spawn(ctx context.Context, dir string) (*arigo.Client, error) {
+	settings := configs.Configs.Settings.Aria2
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// 密钥写入配置文件而不是命令行参数, 避免被其他用户通过进程列表看到
+	conf, err := os.CreateTemp("", "MCST-aria2-*.conf")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(conf.Name()) }()
+	if _, err = fmt.Fprintf(conf, "rpc-secret=%s\n", secret); err != nil {
+		_ = conf.Close()
+		return nil, err
+	}
+	if err = conf.Close(); err != nil {
+		return nil, err
+	}
 
-func (d *Downloader) aria2Download() (string, error) {
 	// 启动Aria2
-	aria2Cmd := cmd.NewCmd("aria2c")
-	aria2Cmd.Args = append(aria2Cmd.Args, configs.Configs.Settings.Aria2.Options...)
-	aria2Cmd.Args = append(aria2Cmd.Args,
-		fmt.Sprintf("--dir=%s", configs.DownloadsDir),
+	b.aria2Cmd = cmd.NewCmd("aria2c")
+	b.aria2Cmd.Args = append(b.aria2Cmd.Args, settings.Options...)
+	b.aria2Cmd.Args = append(b.aria2Cmd.Args,
+		fmt.Sprintf("--conf-path=%s", conf.Name()),
+		fmt.Sprintf("--dir=%s", dir),
 		fmt.Sprintf("--user-agent=MCST/%s", build.Version.GitVersion),
 		"--allow-overwrite=true",
 		"--auto-file-renaming=false",
-		fmt.Sprintf("--retry-wait=%d", configs.Configs.Settings.Aria2.RetryWait),
-		fmt.Sprintf("--split=%d", configs.Configs.Settings.Aria2.Split),
-		fmt.Sprintf("--max-connection-per-server=%d", configs.Configs.Settings.Aria2.MaxConnectionPerServer),
-		fmt.Sprintf("--min-split-size=%s", configs.Configs.Settings.Aria2.MinSplitSize),
+		fmt.Sprintf("--retry-wait=%d", settings.RetryWait),
+		fmt.Sprintf("--split=%d", settings.Split),
+		fmt.Sprintf("--max-connection-per-server=%d", settings.MaxConnectionPerServer),
+		fmt.Sprintf("--min-split-size=%s", settings.MinSplitSize),
 		"--enable-rpc",
-		"--rpc-listen-all",
-		"--rpc-listen-port=6800",
-		"--rpc-secret=MCST",
+		"--rpc-listen-all=false",
+		fmt.Sprintf("--rpc-listen-port=%d", port),
 		"--console-log-level=notice",
-		"--no-conf=true",
 		"--follow-metalink=true",
 		"--metalink-preferred-protocol=https",
 		"--min-tls-version=TLSv1.2",
@@ -59,49 +176,133 @@ func (d *Downloader) aria2Download() (string, error) {
 		"--summary-interval=0",
 		"--auto-save-interval=1",
 	)
-	aria2Cmd.Start()
+	statusChan := b.aria2Cmd.Start()
 
-	// 检测是否启动JSONRPC
+	// 等待JSONRPC启动
+	url := fmt.Sprintf("ws://127.0.0.1:%d/jsonrpc", port)
+	deadline := time.Now().Add(aria2ReadyTimeout)
 	for {
-		status := aria2Cmd.Status()
-		n := len(status.Stdout)
-		if strings.Contains(status.Stdout[n-1], "IPV4 RPC") {
-			break
+		client, err := arigo.Dial(url, secret)
+		if err == nil {
+			if _, err = client.GetVersion(); err == nil {
+				return &client, nil
+			}
+			_ = client.Close()
+		}
+		select {
+		case status := <-statusChan:
+			if status.Error != nil {
+				return nil, status.Error
+			}
+			return nil, fmt.Errorf("%w: 退出代码 %d: %s", MCSTErrors.ErrAria2NotReady, status.Exit, strings.Join(status.Stderr, "\n"))
+		case <-ctx.Done():
+			b.stop()
+			return nil, fmt.Errorf("%w: %w", MCSTErrors.ErrDownloadCanceled, context.Cause(ctx))
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			b.stop()
+			return nil, fmt.Errorf("%w: %v", MCSTErrors.ErrAria2NotReady, err)
 		}
 	}
+}
 
-	// 连接Aria2
-	client, err := arigo.Dial("ws://localhost:6800/jsonrpc", "MCST")
-	if err != nil {
-		return "", err
-	}
-
-	// 获取GID
-	gid, err := client.AddURI(arigo.URIs(d.URL), nil)
-	if err != nil {
-		return "", err
+// stop 停止由 spawn 启动的 aria2c
+func (b *aria2Backend) stop() {
+	if b.aria2Cmd != nil {
+		_ = b.aria2Cmd.Stop()
 	}
+}
 
-	// 更新进度条
+// poll 更新进度直到下载结束; ctx 取消后从 aria2 中移除下载
+func (b *aria2Backend) poll(ctx context.Context) (string, error) {
 	for {
-		status, err := gid.TellStatus("status", "completedLength", "connections")
+		if ctx.Err() != nil {
+			_ = b.Cancel()
+			return "", fmt.Errorf("%w: %w", MCSTErrors.ErrDownloadCanceled, context.Cause(ctx))
+		}
+		status, err := b.gid.TellStatus("status", "totalLength", "completedLength", "connections", "errorMessage")
 		if err != nil {
 			return "", err
 		}
-		if status.Status == StatusComplete {
-			files, err := gid.GetFiles()
+		switch status.Status {
+		case StatusComplete:
+			files, err := b.gid.GetFiles()
 			if err != nil {
 				return "", err
 			}
-			if err = d.bar.Finish(); err != nil {
-				return "", err
-			}
+			b.completed.Store(int64(status.CompletedLength))
 			return files[0].Path, nil
+		case arigo.StatusError:
+			return "", fmt.Errorf("aria2: %s", status.ErrorMessage)
+		case arigo.StatusRemoved:
+			return "", MCSTErrors.ErrDownloadCanceled
 		}
-		d.bar.Describe(fmt.Sprintf("[green]已连接至%d个服务器[reset] [cyan]下载中...[reset]", status.Connections))
-		if err = d.bar.Set64(int64(status.CompletedLength)); err != nil {
-			return "", err
+		if status.TotalLength > 0 {
+			b.total.Store(int64(status.TotalLength))
+		}
+		b.completed.Store(int64(status.CompletedLength))
+		b.connections.Store(int32(status.Connections))
+		select {
+		case <-ctx.Done():
+		case <-time.After(50 * time.Millisecond):
 		}
-		time.Sleep(50 * time.Millisecond)
 	}
 }
+
+// removePartial 删除取消后 aria2 留下的未完成的文件和控制文件
+func (b *aria2Backend) removePartial() {
+	_ = os.Remove(b.task.FilePath)
+	_ = os.Remove(b.task.FilePath + ".aria2")
+}
+
+func (b *aria2Backend) Cancel() error {
+	if b.canceled.Swap(true) {
+		return nil
+	}
+	return b.gid.Remove()
+}
+
+// aria2WebSocketURL 把 http(s) 形式的 RPC 地址转换为 arigo 使用的 ws(s) 地址
+func aria2WebSocketURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	default:
+		return url
+	}
+}
+
+// CheckAria2RPCURL 检查 rpc_url 是否为本机的 aria2; aria2 把文件保存在它所在的机器上, 必须与 MCST 共用文件系统
+func CheckAria2RPCURL(rpcURL string) error {
+	u, err := url.Parse(aria2WebSocketURL(rpcURL))
+	if err != nil {
+		return err
+	}
+	if host := u.Hostname(); host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return fmt.Errorf("%w: %s", MCSTErrors.ErrAria2NotLocal, rpcURL)
+		}
+	}
+	return nil
+}
+
+// freePort 向系统申请一个空闲的本地端口
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = listener.Close() }()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func randomSecret() (string, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// Task 一次下载任务
+type Task struct {
+	URL      string
+	FilePath string         // 下载完成后文件应该在的位置
+	Response *http.Response // 对 URL 的第一次请求的响应, 不需要时可以直接关闭
+	// Verify 校验文件, 实现可以在把临时文件移动到 FilePath 之前调用它
+	Verify func(path string) error
+}
+
+// Progress 下载进度
+type Progress struct {
+	Completed   int64
+	Total       int64 // 未知时为-1
+	Connections int   // 为0时不显示
+}
+
+// Backend 下载后端; Start 不应该阻塞, 下载结果通过 Wait 获取
+//
+// ctx 取消后后端应该停止下载和启动的程序, 并返回 [MCSTErrors.ErrDownloadCanceled]
+type Backend interface {
+	Start(ctx context.Context, task *Task) error
+	Progress() Progress
+	Cancel() error
+	Wait() (string, error)
+}
+
+// BackendFactory 每次下载都会创建一个新的后端
+type BackendFactory func() Backend
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+// RegisterBackend 注册一个下载后端, 之后可以在设置或 --backend 中通过 name 选择它
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = factory
+}
+
+// Backends 返回所有已注册的后端名称
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend 按名称创建一个下载后端
+func NewBackend(name string) (Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrBackendNotFound, name)
+	}
+	return factory(), nil
+}
+
+// progress 供内置后端使用的并发安全的进度计数器, 可以作为 [io.Writer] 统计写入的字节数
+type progress struct {
+	completed   atomic.Int64
+	total       atomic.Int64
+	connections atomic.Int32
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.completed.Add(int64(len(b)))
+	return len(b), nil
+}
+
+func (p *progress) Progress() Progress {
+	return Progress{
+		Completed:   p.completed.Load(),
+		Total:       p.total.Load(),
+		Connections: int(p.connections.Load()),
+	}
+}
+
+// result 保存后台下载的结果
+type result struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+func newResult() *result {
+	return &result{done: make(chan struct{})}
+}
+
+func (r *result) finish(path string, err error) {
+	r.path, r.err = path, err
+	close(r.done)
+}
+
+func (r *result) Wait() (string, error) {
+	<-r.done
+	return r.path, r.err
+}
@@ -0,0 +1,66 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"crypto/sha1" //nolint:gosec // 镜像站只提供SHA-1
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// Checksums 文件的校验值(小写十六进制)
+type Checksums struct {
+	SHA1   string
+	SHA256 string
+}
+
+// FileChecksums 计算文件的 SHA-1 和 SHA-256
+func FileChecksums(path string) (Checksums, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Checksums{}, err
+	}
+	defer func() { _ = file.Close() }()
+	sha1Hash := sha1.New() //nolint:gosec
+	sha256Hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(sha1Hash, sha256Hash), file); err != nil {
+		return Checksums{}, err
+	}
+	return Checksums{
+		SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}, nil
+}
+
+// Verify 检查校验值是否与预期相符, 预期值为空时跳过对应的检查
+func (c Checksums) Verify(sha1, sha256 string) error {
+	if sha1 != "" && !strings.EqualFold(c.SHA1, sha1) {
+		return fmt.Errorf("%w: SHA-1 预期 %s, 实际 %s", MCSTErrors.ErrChecksumMismatch, sha1, c.SHA1)
+	}
+	if sha256 != "" && !strings.EqualFold(c.SHA256, sha256) {
+		return fmt.Errorf("%w: SHA-256 预期 %s, 实际 %s", MCSTErrors.ErrChecksumMismatch, sha256, c.SHA256)
+	}
+	return nil
+}
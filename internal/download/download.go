@@ -19,53 +19,63 @@
 package download
 
 import (
+	"context"
 	"fmt"
-	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 	"github.com/Arama0517/MCST/internal/requests"
 	"github.com/apex/log"
 	"github.com/schollz/progressbar/v3"
 )
 
 type Downloader struct {
-	URL      string
-	FileName string
-	bar      *progressbar.ProgressBar
+	URL       string
+	FileName  string
+	SHA1      string    // 预期的SHA-1, 为空时不校验
+	SHA256    string    // 预期的SHA-256, 为空时不校验
+	Checksums Checksums // 下载完成后文件的实际校验值
+	Backend   string    // 下载后端, 为空时使用设置中的后端
+	bar       *progressbar.ProgressBar
 }
 
 func NewDownloader(url string) *Downloader {
 	return &Downloader{URL: url}
 }
 
-func (d *Downloader) Download() (string, error) {
+// Download 下载文件并返回文件的路径; ctx 取消后停止下载, 返回 [MCSTErrors.ErrDownloadCanceled]
+//
+// 内置后端留下的 .part 文件会在下次下载时继续使用, 其他后端未完成的文件会被删除
+func (d *Downloader) Download(ctx context.Context) (string, error) {
 	// 请求服务器
-	req, err := requests.NewRequest(http.MethodGet, d.URL, nil)
+	req, err := requests.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
 	if err != nil {
 		return "", err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := requests.DoStream(req)
 	if err != nil {
 		return "", err
 	}
 	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", MCSTErrors.ErrBadStatus, resp.Status)
+	}
 
 	// 获取文件名
-	d.FileName = resp.Header.Get("Content-Disposition")
-	if d.FileName == "" {
+	d.FileName = ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		d.FileName = filepath.Base(params["filename"])
+	}
+	if d.FileName == "" || d.FileName == "." || d.FileName == string(filepath.Separator) {
 		d.FileName = filepath.Base(req.URL.Path)
 	}
 
-	// 检测是否已经存在
 	filePath := filepath.Join(configs.DownloadsDir, d.FileName)
-	if _, err = os.Stat(filePath); err == nil {
-		log.Info("检测到文件已存在, 已跳过下载")
-		return filePath, nil
-	}
 
 	// 设置下载进度条
 	d.bar = progressbar.NewOptions64(
@@ -93,22 +103,95 @@ func (d *Downloader) Download() (string, error) {
 		}))
 
 	// 下载
-	if configs.Configs.Settings.Aria2.Enable {
-		return d.aria2Download()
+	name := d.Backend
+	if name == "" {
+		name = configs.Configs.Settings.Backend
 	}
-	return d.defaultDownload(filePath, resp)
-}
-
-// defaultDownload 单线程下载
-func (d *Downloader) defaultDownload(filePath string, resp *http.Response) (string, error) {
-	file, err := os.Create(filePath)
+	backend, err := NewBackend(name)
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = file.Close() }()
-	_, err = io.Copy(io.MultiWriter(file, d.bar), resp.Body)
+	filePath, err = d.run(ctx, backend, &Task{
+		URL:      d.URL,
+		FilePath: filePath,
+		Response: resp,
+		Verify:   d.verify,
+	})
 	if err != nil {
 		return "", err
 	}
+
+	// 校验; 内置后端已经在移动文件前校验过了
+	if d.Checksums == (Checksums{}) {
+		if err = d.verify(filePath); err != nil {
+			_ = os.Remove(filePath)
+			return "", err
+		}
+	}
 	return filePath, nil
 }
+
+// run 启动后端并根据它的进度更新进度条, 直到下载结束
+func (d *Downloader) run(ctx context.Context, backend Backend, task *Task) (string, error) {
+	if err := backend.Start(ctx, task); err != nil {
+		return "", err
+	}
+	type done struct {
+		path string
+		err  error
+	}
+	doneChan := make(chan done, 1)
+	go func() {
+		path, err := backend.Wait()
+		doneChan <- done{path, err}
+	}()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := backend.Cancel(); err != nil {
+				log.WithError(err).Debug("取消下载失败")
+			}
+			// 等待后端停止, 避免它在返回后继续写入文件
+			<-doneChan
+			_ = d.bar.Exit()
+			return "", fmt.Errorf("%w: %w", MCSTErrors.ErrDownloadCanceled, context.Cause(ctx))
+		case result := <-doneChan:
+			if result.err != nil && ctx.Err() != nil {
+				_ = d.bar.Exit()
+				return "", fmt.Errorf("%w: %w", MCSTErrors.ErrDownloadCanceled, context.Cause(ctx))
+			}
+			if result.err != nil {
+				return "", result.err
+			}
+			d.updateBar(backend.Progress())
+			return result.path, d.bar.Finish()
+		case <-ticker.C:
+			d.updateBar(backend.Progress())
+		}
+	}
+}
+
+func (d *Downloader) updateBar(p Progress) {
+	if p.Total > 0 && p.Total != d.bar.GetMax64() {
+		d.bar.ChangeMax64(p.Total)
+	}
+	if p.Connections > 0 {
+		d.bar.Describe(fmt.Sprintf("[green]已连接至%d个服务器[reset] [cyan]下载中...[reset]", p.Connections))
+	}
+	_ = d.bar.Set64(p.Completed)
+}
+
+// verify 计算文件的校验值并与预期值比较
+func (d *Downloader) verify(filePath string) error {
+	checksums, err := FileChecksums(filePath)
+	if err != nil {
+		return err
+	}
+	if err = checksums.Verify(d.SHA1, d.SHA256); err != nil {
+		return err
+	}
+	d.Checksums = checksums
+	return nil
+}
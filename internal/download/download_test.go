@@ -19,11 +19,21 @@
 package download_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Arama0517/MCST/internal/configs"
 	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 )
 
 var URL = "https://dlied4.myapp.com/myapp/1104466820/cos.release-40109/10040714_com.tencent.tmgp.sgame_a2480356_8.2.1.9_F0BvnI.apk"
@@ -38,8 +48,8 @@ func TestDefaultDownload(t *testing.T) {
 	if testing.Short() {
 		return
 	}
-	configs.Configs.Settings.Aria2.Enable = false
-	path, err := download.NewDownloader(URL).Download()
+	configs.Configs.Settings.Backend = download.BackendNative
+	path, err := download.NewDownloader(URL).Download(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,11 +63,11 @@ func TestAria2Download(t *testing.T) {
 	if testing.Short() {
 		return
 	}
-	configs.Configs.Settings.Aria2.Enable = true
+	configs.Configs.Settings.Backend = download.BackendAria2
 	configs.Configs.Settings.Aria2.MaxConnectionPerServer = 16
 	configs.Configs.Settings.Aria2.Split = 32
 	configs.Configs.Settings.Aria2.MinSplitSize = "1M"
-	path, err := download.NewDownloader(URL).Download()
+	path, err := download.NewDownloader(URL).Download(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,3 +76,176 @@ func TestAria2Download(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAria2RPCURL(t *testing.T) {
+	for _, rpcURL := range []string{"ws://127.0.0.1:6800/jsonrpc", "http://localhost:6800/jsonrpc", "ws://[::1]:6800/jsonrpc"} {
+		if err := download.CheckAria2RPCURL(rpcURL); err != nil {
+			t.Errorf("%s: %v", rpcURL, err)
+		}
+	}
+	// 其他机器上的 aria2 下载的文件在本机无法打开
+	for _, rpcURL := range []string{"ws://192.168.1.2:6800/jsonrpc", "https://aria2.example.com/jsonrpc"} {
+		if err := download.CheckAria2RPCURL(rpcURL); !errors.Is(err, MCSTErrors.ErrAria2NotLocal) {
+			t.Errorf("%s: 预期 %v, 实际为 %v", rpcURL, MCSTErrors.ErrAria2NotLocal, err)
+		}
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("truncated"))
+	}))
+	defer server.Close()
+	configs.Configs.Settings.Backend = download.BackendNative
+	downloader := download.NewDownloader(server.URL + "/checksum-mismatch.jar")
+	downloader.SHA1 = "0000000000000000000000000000000000000000"
+	if _, err := downloader.Download(context.Background()); !errors.Is(err, MCSTErrors.ErrChecksumMismatch) {
+		t.Fatalf("预期校验失败, 实际: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configs.DownloadsDir, "checksum-mismatch.jar")); !os.IsNotExist(err) {
+		t.Fatal("校验失败的文件未被删除")
+	}
+}
+
+func TestResumeDownload(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	var rangeRequested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequested = true
+		}
+		w.Header().Set("ETag", `"resume"`)
+		http.ServeContent(w, r, "resume.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	configs.Configs.Settings.Backend = download.BackendNative
+
+	// 模拟一次中断的下载
+	filePath := filepath.Join(configs.DownloadsDir, "resume.jar")
+	if err := os.WriteFile(filePath+".part", content[:10], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state := fmt.Sprintf("url: %s/resume.jar\netag: '\"resume\"'\nlast_modified: \"\"\nsize: %d\n", server.URL, len(content))
+	if err := os.WriteFile(filePath+".part.yaml", []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := download.NewDownloader(server.URL + "/resume.jar").Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(path) }()
+	if !rangeRequested {
+		t.Error("没有使用 Range 继续下载")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(content) {
+		t.Fatalf("文件内容错误: %q", data)
+	}
+	if _, err = os.Stat(filePath + ".part"); !os.IsNotExist(err) {
+		t.Error(".part 文件没有被移除")
+	}
+}
+
+func TestSegmentedDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("MCST"), 16*1024)
+	var rangeRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests.Add(1)
+		}
+		http.ServeContent(w, r, "segmented.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	configs.Configs.Settings.Backend = download.BackendSegmented
+	configs.Configs.Settings.Segmented = configs.Segmented{
+		Split:                  4,
+		MaxConnectionPerServer: 2,
+		MinSplitSize:           "1K",
+	}
+	defer func() { configs.Configs.Settings.Segmented = configs.DefaultSettings.Segmented }()
+
+	path, err := download.NewDownloader(server.URL + "/segmented.jar").Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(path) }()
+	if n := rangeRequests.Load(); n != 4 {
+		t.Errorf("预期4个分段请求, 实际 %d 个", n)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Fatal("文件内容错误")
+	}
+}
+
+// copyBackend 直接写入固定内容, 用于测试自定义后端
+type copyBackend struct {
+	path string
+	err  error
+}
+
+func (b *copyBackend) Start(_ context.Context, task *download.Task) error {
+	_ = task.Response.Body.Close()
+	b.path = task.FilePath
+	b.err = os.WriteFile(task.FilePath, []byte("custom backend"), 0o644)
+	return nil
+}
+
+func (b *copyBackend) Progress() download.Progress { return download.Progress{Total: -1} }
+func (b *copyBackend) Cancel() error               { return nil }
+func (b *copyBackend) Wait() (string, error)       { return b.path, b.err }
+
+func TestCustomBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ignored"))
+	}))
+	defer server.Close()
+	download.RegisterBackend("test", func() download.Backend { return &copyBackend{} })
+
+	downloader := download.NewDownloader(server.URL + "/custom.jar")
+	downloader.Backend = "test"
+	path, err := downloader.Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(path) }()
+	if data, _ := os.ReadFile(path); string(data) != "custom backend" {
+		t.Fatalf("文件内容错误: %q", data)
+	}
+	if downloader.Checksums.SHA256 == "" {
+		t.Error("没有计算校验值")
+	}
+}
+
+func TestCancelDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	configs.Configs.Settings.Backend = download.BackendNative
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	_, err := download.NewDownloader(server.URL + "/cancel.jar").Download(ctx)
+	if !errors.Is(err, MCSTErrors.ErrDownloadCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+	filePath := filepath.Join(configs.DownloadsDir, "cancel.jar")
+	defer func() {
+		_ = os.Remove(filePath + ".part")
+		_ = os.Remove(filePath + ".part.yaml")
+	}()
+	if _, err = os.Stat(filePath); !os.IsNotExist(err) {
+		t.Fatal("取消的下载不应该生成文件")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/go-cmd/cmd"
+)
+
+const (
+	BackendExternal = "external"
+	BackendIDM      = "idm"
+)
+
+func init() {
+	RegisterBackend(BackendExternal, func() Backend {
+		return &externalBackend{
+			command: configs.Configs.Settings.External.Command,
+			args:    configs.Configs.Settings.External.Args,
+		}
+	})
+	RegisterBackend(BackendIDM, func() Backend {
+		return &externalBackend{
+			command: filepath.Join(configs.Configs.Settings.IDM.RootDir, "IDMan.exe"),
+			args:    []string{"/d", "{url}", "/p", "{dir}", "/f", "{file}", "/n", "/q"},
+			// IDMan.exe 把任务交给正在运行的 IDM 后就会退出, 需要等待文件出现
+			waitForFile: true,
+		}
+	})
+}
+
+// externalBackend 调用外部程序下载, 参数中的 {url} {dir} {file} {path} 会被替换
+type externalBackend struct {
+	*result
+	command     string
+	args        []string
+	waitForFile bool
+
+	task     *Task
+	cmd      *cmd.Cmd
+	canceled atomic.Bool
+}
+
+func (b *externalBackend) Start(ctx context.Context, task *Task) error {
+	if b.command == "" {
+		return MCSTErrors.ErrExternalCommandNotSet
+	}
+	// 外部程序会自己发起请求
+	_ = task.Response.Body.Close()
+	b.task = task
+	b.result = newResult()
+	replacer := strings.NewReplacer(
+		"{url}", task.URL,
+		"{dir}", filepath.Dir(task.FilePath),
+		"{file}", filepath.Base(task.FilePath),
+		"{path}", task.FilePath,
+	)
+	args := make([]string, 0, len(b.args))
+	for _, arg := range b.args {
+		args = append(args, replacer.Replace(arg))
+	}
+	b.cmd = cmd.NewCmd(b.command, args...)
+	statusChan := b.cmd.Start()
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = b.Cancel()
+		case <-b.done:
+		}
+	}()
+	go func() {
+		path, err := b.wait(statusChan)
+		if b.canceled.Load() {
+			// 外部程序未完成的文件无法继续使用
+			_ = os.Remove(task.FilePath)
+		}
+		b.finish(path, err)
+	}()
+	return nil
+}
+
+func (b *externalBackend) wait(statusChan <-chan cmd.Status) (string, error) {
+	status := <-statusChan
+	switch {
+	case b.canceled.Load():
+		return "", MCSTErrors.ErrDownloadCanceled
+	case status.Error != nil:
+		return "", status.Error
+	case status.Exit != 0:
+		return "", fmt.Errorf("%s 退出代码 %d: %s", b.command, status.Exit, strings.Join(status.Stderr, "\n"))
+	}
+	if !b.waitForFile {
+		if _, err := os.Stat(b.task.FilePath); err != nil {
+			return "", err
+		}
+		return b.task.FilePath, nil
+	}
+
+	// 等待文件出现并且大小不再变化
+	var lastSize int64 = -1
+	for !b.canceled.Load() {
+		time.Sleep(time.Second)
+		info, err := os.Stat(b.task.FilePath)
+		if err != nil {
+			continue
+		}
+		if info.Size() == lastSize {
+			return b.task.FilePath, nil
+		}
+		lastSize = info.Size()
+	}
+	return "", MCSTErrors.ErrDownloadCanceled
+}
+
+func (b *externalBackend) Progress() Progress {
+	p := Progress{Total: b.task.Response.ContentLength}
+	if info, err := os.Stat(b.task.FilePath); err == nil {
+		p.Completed = info.Size()
+	}
+	return p
+}
+
+func (b *externalBackend) Cancel() error {
+	b.canceled.Store(true)
+	return b.cmd.Stop()
+}
@@ -0,0 +1,156 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/requests"
+	"github.com/apex/log"
+)
+
+const BackendNative = "native"
+
+func init() {
+	RegisterBackend(BackendNative, func() Backend { return &nativeBackend{} })
+}
+
+// nativeBackend 单线程下载
+type nativeBackend struct {
+	progress
+	*result
+	task   *Task
+	cancel context.CancelFunc
+}
+
+func (b *nativeBackend) Start(ctx context.Context, task *Task) error {
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.task = task
+	b.result = newResult()
+	go func() {
+		b.finish(nativeDownload(ctx, task, &b.progress))
+	}()
+	return nil
+}
+
+func (b *nativeBackend) Cancel() error {
+	b.cancel()
+	return b.task.Response.Body.Close()
+}
+
+// nativeDownload 单线程下载; 数据先写入 .part 文件, 如果服务器支持 Range 则从上次中断的位置继续
+func nativeDownload(ctx context.Context, task *Task, p *progress) (string, error) {
+	resp := task.Response
+	partPath := task.FilePath + partSuffix
+	statePath := task.FilePath + partStateSuffix
+	state := newPartState(task.URL, resp)
+
+	// 检测能否断点续传
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil && info.Size() > 0 {
+		if previous, err := loadPartState(statePath); err == nil && previous.resumable(state) &&
+			(state.Size < 0 || info.Size() < state.Size) {
+			rangeResp, err := requestRange(ctx, task.URL, fmt.Sprintf("bytes=%d-", info.Size()), state.validator())
+			if err != nil {
+				return "", err
+			}
+			defer func() { _ = rangeResp.Body.Close() }()
+			switch rangeResp.StatusCode {
+			case http.StatusPartialContent:
+				if start, ok := contentRangeStart(rangeResp.Header.Get("Content-Range")); ok && start == info.Size() {
+					log.WithField("offset", info.Size()).Info("继续下载未完成的文件")
+					offset = info.Size()
+					resp = rangeResp
+				}
+			case http.StatusOK:
+				// 文件已经改变, 服务器直接返回了完整的文件
+				resp = rangeResp
+				state = newPartState(task.URL, resp)
+			}
+		}
+	}
+	if err := state.save(statePath); err != nil {
+		return "", err
+	}
+
+	// 写入 .part 文件
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(partPath, flag, 0o644)
+	if err != nil {
+		return "", err
+	}
+	p.total.Store(state.Size)
+	p.completed.Store(offset)
+	p.connections.Store(1)
+	written, err := io.Copy(io.MultiWriter(file, p), resp.Body)
+	p.connections.Store(0)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 检查长度和校验值, 都通过后再移动到下载目录
+	if state.Size >= 0 && offset+written != state.Size {
+		return "", fmt.Errorf("%w: 预期 %d 字节, 实际 %d 字节", MCSTErrors.ErrIncompleteDownload, state.Size, offset+written)
+	}
+	if err = task.Verify(partPath); err != nil {
+		_ = os.Remove(partPath)
+		_ = os.Remove(statePath)
+		return "", err
+	}
+	if err = os.Rename(partPath, task.FilePath); err != nil {
+		return "", err
+	}
+	_ = os.Remove(statePath)
+	return task.FilePath, nil
+}
+
+// requestRange 请求文件的一部分, validator 不为空且与服务器上的文件不匹配时服务器会返回完整的文件
+func requestRange(ctx context.Context, url, byteRange, validator string) (*http.Response, error) {
+	req, err := requests.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Range", byteRange)
+	if validator != "" {
+		req.Header.Set("If-Range", validator)
+	}
+	return requests.DoStream(req)
+}
+
+// contentRangeStart 解析 "bytes start-end/size" 格式的 Content-Range
+func contentRangeStart(contentRange string) (int64, bool) {
+	var start, end int64
+	var size string
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%s", &start, &end, &size); err != nil {
+		return 0, false
+	}
+	return start, true
+}
@@ -0,0 +1,87 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"net/http"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	partSuffix      = ".part"
+	partStateSuffix = ".part.yaml"
+)
+
+// partState 记录未完成下载的信息, 用于判断能否断点续传
+type partState struct {
+	URL          string `yaml:"url"`
+	ETag         string `yaml:"etag"`
+	LastModified string `yaml:"last_modified"`
+	Size         int64  `yaml:"size"` // 文件总大小, 未知时为-1
+}
+
+func newPartState(url string, resp *http.Response) partState {
+	return partState{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Size:         resp.ContentLength,
+	}
+}
+
+// validator 返回用于 If-Range 的值; 优先使用强 ETag, 为空时表示无法续传
+func (s partState) validator() string {
+	if s.ETag != "" && !isWeakETag(s.ETag) {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+// resumable 判断之前的下载是否对应同一个文件
+func (s partState) resumable(current partState) bool {
+	return s.validator() != "" &&
+		s.URL == current.URL &&
+		s.ETag == current.ETag &&
+		s.LastModified == current.LastModified &&
+		s.Size == current.Size
+}
+
+func isWeakETag(etag string) bool {
+	return len(etag) >= 2 && etag[:2] == "W/"
+}
+
+func loadPartState(path string) (partState, error) {
+	var state partState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	err = yaml.Unmarshal(data, &state)
+	return state, err
+}
+
+func (s partState) save(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,191 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/Arama0517/MCST/internal/bytes"
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/apex/log"
+)
+
+const BackendSegmented = "segmented"
+
+func init() {
+	RegisterBackend(BackendSegmented, func() Backend { return &segmentedBackend{} })
+}
+
+type segment struct {
+	start, end int64 // 闭区间, 与 Range 头一致
+}
+
+// splitSegments 按照 aria2 的 --split 和 --min-split-size 语义划分分段
+func splitSegments(size int64, split int, minSplitSize int64) []segment {
+	count := int64(split)
+	if minSplitSize > 0 && size/minSplitSize < count {
+		count = size / minSplitSize
+	}
+	if count < 1 {
+		count = 1
+	}
+	segmentSize := size / count
+	segments := make([]segment, 0, count)
+	for i := int64(0); i < count; i++ {
+		start := i * segmentSize
+		end := start + segmentSize - 1
+		if i == count-1 {
+			end = size - 1
+		}
+		segments = append(segments, segment{start: start, end: end})
+	}
+	return segments
+}
+
+// segmentedBackend 多线程分段下载; 服务器不支持 Range 时回退到单线程下载
+type segmentedBackend struct {
+	progress
+	*result
+	task   *Task
+	cancel context.CancelFunc
+}
+
+func (b *segmentedBackend) Start(ctx context.Context, task *Task) error {
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.task = task
+	b.result = newResult()
+	go func() {
+		b.finish(segmentedDownload(ctx, task, &b.progress))
+	}()
+	return nil
+}
+
+func (b *segmentedBackend) Cancel() error {
+	b.cancel()
+	return b.task.Response.Body.Close()
+}
+
+func segmentedDownload(ctx context.Context, task *Task, p *progress) (string, error) {
+	resp := task.Response
+	settings := configs.Configs.Settings.Segmented
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength <= 0 {
+		log.Debug("服务器不支持分段下载, 使用单线程下载")
+		return nativeDownload(ctx, task, p)
+	}
+	minSplitSize, err := bytes.ToBytes(settings.MinSplitSize)
+	if err != nil {
+		return "", err
+	}
+	segments := splitSegments(resp.ContentLength, settings.Split, int64(minSplitSize))
+	if len(segments) == 1 {
+		return nativeDownload(ctx, task, p)
+	}
+	_ = resp.Body.Close()
+	validator := newPartState(task.URL, resp).validator()
+
+	// 分段下载不支持续传, 清理之前留下的文件
+	partPath := task.FilePath + partSuffix
+	_ = os.Remove(task.FilePath + partStateSuffix)
+	file, err := os.Create(partPath)
+	if err != nil {
+		return "", err
+	}
+	if err = file.Truncate(resp.ContentLength); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	p.total.Store(resp.ContentLength)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	connections := min(settings.MaxConnectionPerServer, len(segments))
+	if connections < 1 {
+		connections = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	queue := make(chan segment, len(segments))
+	for _, s := range segments {
+		queue <- s
+	}
+	close(queue)
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range queue {
+				p.connections.Add(1)
+				err := downloadSegment(ctx, task.URL, file, s, validator, p)
+				p.connections.Add(-1)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err = file.Close(); firstErr == nil {
+		firstErr = err
+	}
+	if firstErr != nil {
+		_ = os.Remove(partPath)
+		return "", firstErr
+	}
+
+	if err = task.Verify(partPath); err != nil {
+		_ = os.Remove(partPath)
+		return "", err
+	}
+	if err = os.Rename(partPath, task.FilePath); err != nil {
+		return "", err
+	}
+	return task.FilePath, nil
+}
+
+func downloadSegment(ctx context.Context, url string, file *os.File, s segment, validator string, p *progress) error {
+	resp, err := requestRange(ctx, url, fmt.Sprintf("bytes=%d-%d", s.start, s.end), validator)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("%w: %s", MCSTErrors.ErrBadStatus, resp.Status)
+	}
+	written, err := io.Copy(io.MultiWriter(io.NewOffsetWriter(file, s.start), p), resp.Body)
+	if err != nil {
+		return err
+	}
+	if written != s.end-s.start+1 {
+		return fmt.Errorf("%w: 分段 %d-%d 只收到 %d 字节", MCSTErrors.ErrIncompleteDownload, s.start, s.end, written)
+	}
+	return nil
+}
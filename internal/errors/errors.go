@@ -41,8 +41,75 @@ var ErrEulaRequired = errors.New("微软要求必须同意EULA协议(https://aka
 
 var ErrCoreNotFound = errors.New("核心不存在")
 
+var ErrNoBackup = errors.New("没有可以回滚的备份")
+
+var (
+	ErrProviderNotFound  = errors.New("核心来源不存在")
+	ErrVersionRequired   = errors.New("此来源需要指定Minecraft版本")
+	ErrBuildRequired     = errors.New("此来源需要指定构建版本")
+	ErrCoreRequired      = errors.New("此来源有多个核心, 需要使用 --core 指定")
+	ErrNoMatchingVersion = errors.New("没有符合条件的版本")
+	ErrNoServerJar       = errors.New("此版本没有服务端")
+	ErrNotInstaller      = errors.New("核心不是安装器")
+	ErrInstallFailed     = errors.New("安装器运行失败")
+	ErrInstallerUpgrade  = errors.New("只有使用 -jar server.jar 启动的服务器可以升级, 请重新创建服务器")
+)
+
+var (
+	ErrChecksumMismatch   = errors.New("文件校验失败, 文件可能已损坏")
+	ErrIncompleteDownload = errors.New("下载不完整")
+	ErrBadStatus          = errors.New("服务器返回了错误的状态码")
+	ErrDownloadCanceled   = errors.New("下载已取消")
+	ErrInvalidSHA256      = errors.New("这不是一个有效的 SHA-256")
+	ErrStoreCorrupted     = errors.New("仓库中有损坏的文件")
+)
+
+var (
+	ErrBackendNotFound       = errors.New("下载后端不存在")
+	ErrExternalCommandNotSet = errors.New("未设置外部下载程序, 请使用 'MCST settings' 设置")
+	ErrAria2NotReady         = errors.New("aria2c 未能启动 JSON-RPC")
+	ErrAria2NotLocal         = errors.New("aria2 的 JSON-RPC 地址必须是本机的回环地址, aria2 需要与 MCST 共用文件系统")
+)
+
 const (
 	InitConfigFail = iota + 1
 	InitLocaleFail
 	RunFail
+	Canceled // 被 Ctrl+C 或 SIGTERM 取消
 )
+
+var (
+	ErrUnknownLaunchMode = errors.New("未知的启动方式")
+	ErrLaunchIncomplete  = errors.New("启动方式缺少必要的设置")
+	ErrUnknownRestart    = errors.New("未知的自动重启策略, 请使用 never, on-failure 或 always")
+)
+
+var (
+	ErrOffline   = errors.New("离线模式下无法发起网络请求")
+	ErrNotCached = errors.New("离线模式下缓存中没有此数据")
+)
+
+var (
+	ErrUnsupportedProxy = errors.New("不支持的代理协议, 请使用 http, https 或 socks5")
+	ErrInvalidCABundle  = errors.New("CA证书文件中没有有效的PEM证书")
+	ErrReadTimeout      = errors.New("服务器在规定的时间内没有发送数据")
+)
+
+var ErrUnknownOutputFormat = errors.New("未知的输出格式, 请使用 table, json 或 yaml")
+
+var (
+	ErrDaemonRunning    = errors.New("守护进程已在运行")
+	ErrDaemonNotRunning = errors.New("守护进程没有运行, 请使用 'MCST daemon' 启动")
+	ErrDaemonNotReady   = errors.New("守护进程未能启动, 请查看 run/daemon.log")
+	ErrServerRunning    = errors.New("服务器已在运行")
+	ErrServerNotRunning = errors.New("服务器没有运行")
+	ErrUnknownAction    = errors.New("守护进程不支持此操作, 请重启守护进程")
+)
+
+var (
+	ErrRCONDisabled = errors.New("服务器没有启用 RCON")
+	ErrRCONAuth     = errors.New("RCON 密码错误")
+	ErrRCONPacket   = errors.New("RCON 数据包无效")
+)
+
+var ErrBadPingResponse = errors.New("服务器返回了无效的 Server List Ping 回复")
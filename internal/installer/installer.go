@@ -0,0 +1,143 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/store"
+	"github.com/apex/log"
+	"github.com/go-cmd/cmd"
+)
+
+// Run 在服务器目录 dir 中运行安装器核心, 返回安装后启动服务器的方式; ctx 取消后停止安装器
+func Run(ctx context.Context, javaPath, dir string, core configs.Core) (configs.Launch, error) {
+	if core.Installer == nil {
+		return configs.Launch{}, MCSTErrors.ErrNotInstaller
+	}
+	installerPath := filepath.Join(dir, core.FileName)
+	if err := store.Link(core.FilePath, installerPath); err != nil {
+		return configs.Launch{}, err
+	}
+	defer func() { _ = os.Remove(installerPath) }()
+
+	log.WithField("installer", core.FileName).Info("正在运行安装器")
+	installCmd := cmd.NewCmdOptions(cmd.Options{Buffered: false, Streaming: true}, javaPath)
+	installCmd.Dir = dir
+	installCmd.Args = append(installCmd.Args, "-jar", core.FileName)
+	installCmd.Args = append(installCmd.Args, core.Installer.Args...)
+	statusChan := installCmd.Start()
+
+	// 输出通道会在进程退出后关闭
+	var stderr []string
+	stdoutChan, stderrChan := installCmd.Stdout, installCmd.Stderr
+	done := ctx.Done()
+	for stdoutChan != nil || stderrChan != nil {
+		select {
+		case <-done:
+			log.Warn("正在停止安装器")
+			_ = installCmd.Stop()
+			done = nil
+		case line, ok := <-stdoutChan:
+			if !ok {
+				stdoutChan = nil
+				continue
+			}
+			log.Debug(line)
+		case line, ok := <-stderrChan:
+			if !ok {
+				stderrChan = nil
+				continue
+			}
+			log.Debug(line)
+			stderr = append(stderr, line)
+		}
+	}
+	status := <-statusChan
+	if ctx.Err() != nil {
+		return configs.Launch{}, context.Cause(ctx)
+	}
+	if status.Error != nil {
+		return configs.Launch{}, status.Error
+	}
+	if status.Exit != 0 {
+		return configs.Launch{}, fmt.Errorf("%w: 退出代码 %d: %s", MCSTErrors.ErrInstallFailed, status.Exit, strings.Join(stderr, "\n"))
+	}
+
+	launch, err := detect(dir, core)
+	if err != nil {
+		return configs.Launch{}, err
+	}
+	log.Info("安装完成")
+	return launch, nil
+}
+
+// detect 查找安装结果; 优先使用启动脚本中的参数文件
+func detect(dir string, core configs.Core) (configs.Launch, error) {
+	script := "run.sh"
+	if runtime.GOOS == "windows" {
+		script = "run.bat"
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, script)); err == nil {
+		if argFiles := ParseArgFiles(string(data)); len(argFiles) > 0 {
+			return configs.Launch{Mode: configs.LaunchArgFile, ArgFiles: argFiles}, nil
+		}
+	}
+	if core.Installer.Jar != "" {
+		matches, err := filepath.Glob(filepath.Join(dir, core.Installer.Jar))
+		if err != nil {
+			return configs.Launch{}, err
+		}
+		for _, match := range matches {
+			// 安装器本身也可能匹配
+			if name := filepath.Base(match); name != core.FileName {
+				return configs.Launch{Mode: configs.LaunchJar, Jar: name}, nil
+			}
+		}
+	}
+	return configs.Launch{}, fmt.Errorf("%w: 没有找到 %s 或 %s", MCSTErrors.ErrInstallFailed, script, core.Installer.Jar)
+}
+
+// ParseArgFiles 从启动脚本中找出 Java 参数文件, 例如 "java @user_jvm_args.txt @libraries/.../unix_args.txt "$@"" 中的两个文件
+func ParseArgFiles(script string) []string {
+	for _, line := range strings.Split(script, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.Contains(strings.ToLower(fields[0]), "java") {
+			continue
+		}
+		var argFiles []string
+		for _, field := range fields[1:] {
+			field = strings.Trim(field, `"'`)
+			if strings.HasPrefix(field, "@") {
+				argFiles = append(argFiles, strings.TrimSpace(field[1:]))
+			}
+		}
+		if len(argFiles) > 0 {
+			return argFiles
+		}
+	}
+	return nil
+}
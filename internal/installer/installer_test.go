@@ -0,0 +1,85 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package installer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/installer"
+)
+
+func TestParseArgFiles(t *testing.T) {
+	script := `#!/usr/bin/env sh
+# Forge requires a configured set of both JVM and program arguments.
+# Add custom JVM arguments to the user_jvm_args.txt
+java @user_jvm_args.txt @libraries/net/minecraftforge/forge/1.20.1-47.1.0/unix_args.txt "$@"
+`
+	want := []string{"user_jvm_args.txt", "libraries/net/minecraftforge/forge/1.20.1-47.1.0/unix_args.txt"}
+	if got := installer.ParseArgFiles(script); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseArgFiles() = %v, want %v", got, want)
+	}
+	if got := installer.ParseArgFiles("java -jar server.jar"); got != nil {
+		t.Fatalf("ParseArgFiles() = %v, want nil", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 脚本代替 Java")
+	}
+	dir := t.TempDir()
+	serverDir := filepath.Join(dir, "server")
+	if err := os.Mkdir(serverDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	installerPath := filepath.Join(dir, "forge-1.20.1-47.1.0-installer.jar")
+	if err := os.WriteFile(installerPath, []byte("installer"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟 'java -jar forge-installer.jar --installServer'
+	java := filepath.Join(dir, "java")
+	if err := os.WriteFile(java, []byte(`#!/bin/sh
+[ "$3" = "--installServer" ] || exit 1
+echo 'java @user_jvm_args.txt @libraries/unix_args.txt "$@"' > run.sh
+`), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	launch, err := installer.Run(context.Background(), java, serverDir, configs.Core{
+		FileName:  filepath.Base(installerPath),
+		FilePath:  installerPath,
+		Installer: &configs.Installer{Args: []string{"--installServer"}, Jar: "forge-*.jar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := configs.Launch{Mode: configs.LaunchArgFile, ArgFiles: []string{"user_jvm_args.txt", "libraries/unix_args.txt"}}
+	if !reflect.DeepEqual(launch, want) {
+		t.Fatalf("launch = %+v, want %+v", launch, want)
+	}
+	if _, err = os.Stat(filepath.Join(serverDir, filepath.Base(installerPath))); !os.IsNotExist(err) {
+		t.Fatal("安装后应该删除安装器")
+	}
+}
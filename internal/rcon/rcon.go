@@ -0,0 +1,139 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package rcon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/properties"
+)
+
+// 数据包的类型
+const (
+	typeCommand = 2
+	typeLogin   = 3
+)
+
+// defaultPort server.properties 中没有设置 rcon.port 时使用的端口
+const defaultPort = "25575"
+
+// maxPacketSize 服务器发送的数据包的最大长度
+const maxPacketSize = 4096 + 10
+
+// Client Minecraft 的 RCON 客户端
+type Client struct {
+	conn net.Conn
+	id   int32
+}
+
+// Dial 连接 address 并使用 password 登录, timeout 同时限制之后每次命令的时间
+func Dial(address, password string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	id, _, err := c.request(typeLogin, password)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	// 密码错误时服务器返回的 id 为 -1
+	if id == -1 {
+		_ = conn.Close()
+		return nil, MCSTErrors.ErrRCONAuth
+	}
+	return c, nil
+}
+
+// DialServer 使用服务器目录中 server.properties 的设置连接本机的服务器
+func DialServer(dir string, timeout time.Duration) (*Client, error) {
+	values, err := properties.Load(filepath.Join(dir, "server.properties"))
+	if err != nil {
+		return nil, err
+	}
+	if values["enable-rcon"] != "true" || values["rcon.password"] == "" {
+		return nil, MCSTErrors.ErrRCONDisabled
+	}
+	port := values["rcon.port"]
+	if port == "" {
+		port = defaultPort
+	}
+	return Dial(net.JoinHostPort("127.0.0.1", port), values["rcon.password"], timeout)
+}
+
+// Command 执行一条命令并返回结果
+func (c *Client) Command(command string) (string, error) {
+	_, body, err := c.request(typeCommand, command)
+	return body, err
+}
+
+// Send 发送一条命令, 不等待结果; 用于 stop 这样执行后服务器会关闭连接的命令
+func (c *Client) Send(command string) error {
+	c.id++
+	return c.write(c.id, typeCommand, command)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) request(packetType int32, body string) (int32, string, error) {
+	c.id++
+	if err := c.write(c.id, packetType, body); err != nil {
+		return 0, "", err
+	}
+	return c.read()
+}
+
+// write 发送数据包: 长度, id, 类型, 以 \x00 结尾的内容, 再加一个 \x00
+func (c *Client) write(id, packetType int32, body string) error {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, int32(len(body)+10))
+	_ = binary.Write(&buf, binary.LittleEndian, id)
+	_ = binary.Write(&buf, binary.LittleEndian, packetType)
+	buf.WriteString(body)
+	buf.Write([]byte{0, 0})
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+func (c *Client) read() (int32, string, error) {
+	var size int32
+	if err := binary.Read(c.conn, binary.LittleEndian, &size); err != nil {
+		return 0, "", err
+	}
+	if size < 10 || size > maxPacketSize {
+		return 0, "", fmt.Errorf("%w: %d", MCSTErrors.ErrRCONPacket, size)
+	}
+	packet := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, packet); err != nil {
+		return 0, "", err
+	}
+	id := int32(binary.LittleEndian.Uint32(packet[0:4]))
+	return id, string(packet[8 : size-2]), nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package rcon_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/rcon"
+)
+
+// serveRCON 模拟 Minecraft 的 RCON: 密码为 secret, 命令的结果为 "ran <命令>"; 收到 halt 时不回复直接关闭连接
+func serveRCON(listener net.Listener) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	for {
+		var size, id, packetType int32
+		if err = binary.Read(conn, binary.LittleEndian, &size); err != nil {
+			return
+		}
+		_ = binary.Read(conn, binary.LittleEndian, &id)
+		_ = binary.Read(conn, binary.LittleEndian, &packetType)
+		body := make([]byte, size-8)
+		if _, err = io.ReadFull(conn, body); err != nil {
+			return
+		}
+		payload := string(body[:len(body)-2])
+		reply := ""
+		switch {
+		case packetType == 3 && payload != "secret":
+			id = -1
+		case packetType == 2 && payload == "halt":
+			return
+		case packetType == 2:
+			reply = "ran " + payload
+		}
+		_ = binary.Write(conn, binary.LittleEndian, int32(len(reply)+10))
+		_ = binary.Write(conn, binary.LittleEndian, id)
+		_ = binary.Write(conn, binary.LittleEndian, int32(0))
+		_, _ = conn.Write(append([]byte(reply), 0, 0))
+	}
+}
+
+func TestRCON(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	go serveRCON(listener)
+
+	dir := t.TempDir()
+	properties := fmt.Sprintf("# Minecraft server properties\nenable-rcon=true\nrcon.port=%d\nrcon.password=secret\n",
+		listener.Addr().(*net.TCPAddr).Port)
+	if err = os.WriteFile(filepath.Join(dir, "server.properties"), []byte(properties), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := rcon.DialServer(dir, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	result, err := client.Command("stop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "ran stop" {
+		t.Fatalf("预期 %q, 实际为 %q", "ran stop", result)
+	}
+
+	// 服务器执行命令后关闭连接时 Send 不会失败
+	if err = client.Send("halt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRCONAuth(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	go serveRCON(listener)
+
+	if _, err = rcon.Dial(listener.Addr().String(), "wrong", time.Second); !errors.Is(err, MCSTErrors.ErrRCONAuth) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrRCONAuth, err)
+	}
+	if _, err = rcon.DialServer(t.TempDir(), time.Second); err == nil {
+		t.Fatal("没有 server.properties 时应该返回错误")
+	}
+}
@@ -0,0 +1,288 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/apex/log"
+)
+
+// maxRetryWait 两次重试之间最长的等待时间
+const maxRetryWait = 30 * time.Second
+
+// Do 使用网络设置中的超时, 代理和CA证书发送请求, 替代 [http.DefaultClient]
+//
+// 遇到网络错误或服务器返回 5xx 时按指数退避重试, 仍然失败时按顺序尝试 [MirrorURLs] 中的镜像
+func Do(req *http.Request) (*http.Response, error) {
+	return do(req, false)
+}
+
+// DoStream 与 [Do] 相同, 但没有整体超时, 用于下载文件; 读取响应时超过网络设置的超时时间没有收到数据会返回 [MCSTErrors.ErrReadTimeout]
+func DoStream(req *http.Request) (*http.Response, error) {
+	return do(req, true)
+}
+
+func do(req *http.Request, stream bool) (*http.Response, error) {
+	network := configs.Configs.Settings.Network
+	client, err := getClient(network, stream)
+	if err != nil {
+		return nil, err
+	}
+	if !stream {
+		return doMirrors(client, req, network)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := doMirrors(client, req.WithContext(ctx), network)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	_, timeout, _ := network.Timeouts()
+	resp.Body = newIdleTimeoutBody(resp.Body, timeout, cancel)
+	return resp, nil
+}
+
+// doMirrors 依次使用原地址和镜像发送请求
+func doMirrors(client *http.Client, req *http.Request, network configs.Network) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	for i, mirrorURL := range MirrorURLs(req.URL.String()) {
+		if i > 0 {
+			if resp != nil {
+				closeResponse(resp)
+			}
+			log.WithError(err).WithField("url", mirrorURL).Warn("请求失败, 尝试使用镜像")
+			if req, err = withURL(req, mirrorURL); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = doRetry(client, req, network)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			return resp, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%w: %s", MCSTErrors.ErrBadStatus, resp.Status)
+		}
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	// 所有地址都返回了 5xx 时把最后的响应交给调用者处理
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
+// doRetry 发送请求, 遇到网络错误或 5xx 时重试 network.Retries 次
+func doRetry(client *http.Client, req *http.Request, network configs.Network) (*http.Response, error) {
+	_, _, wait := network.Timeouts()
+	// 请求体无法重新读取时不能重试
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if !canRetry || attempt > network.Retries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		entry := log.WithField("url", req.URL.String()).WithField("attempt", attempt)
+		if err != nil {
+			entry = entry.WithError(err)
+		} else {
+			entry = entry.WithField("status", resp.Status)
+			closeResponse(resp)
+		}
+		entry.WithField("wait", wait).Debug("请求失败, 等待后重试")
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		wait = min(wait*2, maxRetryWait)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// idleTimeoutBody 每次读取超过 timeout 没有返回时取消请求, 避免服务器发送响应头后不再发送数据时一直等待
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timer    *time.Timer
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	timedOut atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.timedOut.Store(true)
+		cancel()
+	})
+	b.timer.Stop()
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	b.timer.Reset(b.timeout)
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Stop()
+	if err != nil && b.timedOut.Load() {
+		return n, fmt.Errorf("%w: %s", MCSTErrors.ErrReadTimeout, b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// closeResponse 读完并关闭响应, 使连接可以被复用
+func closeResponse(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+}
+
+// withURL 返回使用另一个地址的请求副本
+func withURL(req *http.Request, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.URL = u
+	clone.Host = ""
+	if req.GetBody != nil {
+		if clone.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return clone, nil
+}
+
+// MirrorURLs 返回 rawURL 和它的镜像地址, 镜像按设置中最长的匹配前缀替换, 按顺序排列
+func MirrorURLs(rawURL string) []string {
+	urls := []string{rawURL}
+	var prefix string
+	for p := range configs.Configs.Settings.Network.Mirrors {
+		if strings.HasPrefix(rawURL, p) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return urls
+	}
+	for _, mirror := range configs.Configs.Settings.Network.Mirrors[prefix] {
+		urls = append(urls, mirror+strings.TrimPrefix(rawURL, prefix))
+	}
+	return urls
+}
+
+// clientKey 影响 [http.Client] 的设置, 设置不变时复用同一个客户端以复用连接
+type clientKey struct {
+	connectTimeout, timeout, proxy, caBundle string
+	stream                                   bool
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[clientKey]*http.Client{}
+)
+
+func getClient(network configs.Network, stream bool) (*http.Client, error) {
+	key := clientKey{network.ConnectTimeout, network.Timeout, network.Proxy, network.CABundle, stream}
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := clients[key]; ok {
+		return client, nil
+	}
+	client, err := newClient(network, stream)
+	if err != nil {
+		return nil, err
+	}
+	clients[key] = client
+	return client, nil
+}
+
+func newClient(network configs.Network, stream bool) (*http.Client, error) {
+	connectTimeout, timeout, _ := network.Timeouts()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	if network.Proxy != "" {
+		proxyURL, err := url.Parse(network.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrUnsupportedProxy, network.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if network.CABundle != "" {
+		pem, err := os.ReadFile(network.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrInvalidCABundle, network.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	client := &http.Client{Transport: transport}
+	if stream {
+		transport.ResponseHeaderTimeout = timeout
+	} else {
+		client.Timeout = timeout
+	}
+	return client, nil
+}
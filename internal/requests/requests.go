@@ -19,16 +19,31 @@
 package requests
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/Arama0517/MCST/internal/build"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 )
 
+// Offline 由 --offline 设置, 为 true 时不发起任何网络请求
+var Offline bool
+
 // NewRequest 替代 [http.NewRequest]; 此函数的作用是在 [http.NewRequest] 函数的基础上默认添加 User-Agent
+//
+// 离线模式下返回 [MCSTErrors.ErrOffline]
 func NewRequest(method string, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body) //nolint:forbidigo
+	return NewRequestWithContext(context.Background(), method, url, body)
+}
+
+// NewRequestWithContext 与 [NewRequest] 相同, ctx 取消后请求和重试都会停止
+func NewRequestWithContext(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
+	if Offline {
+		return nil, fmt.Errorf("%w: %s", MCSTErrors.ErrOffline, url)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body) //nolint:forbidigo
 	if err != nil {
 		return nil, err
 	}
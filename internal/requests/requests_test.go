@@ -19,21 +19,164 @@
 package requests_test
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 	"github.com/Arama0517/MCST/internal/requests"
 )
 
+// setNetwork 在测试结束后恢复网络设置
+func setNetwork(t *testing.T, network configs.Network) {
+	t.Helper()
+	old := configs.Configs.Settings.Network
+	t.Cleanup(func() { configs.Configs.Settings.Network = old })
+	configs.Configs.Settings.Network = network
+}
+
 func TestNewRequest(t *testing.T) {
-	req, err := requests.NewRequest(http.MethodGet, "https://example.com", nil)
+	setNetwork(t, configs.Network{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.UserAgent(), "MCST/") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	req, err := requests.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := requests.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %s", resp.Status)
+	}
+
+	requests.Offline = true
+	defer func() { requests.Offline = false }()
+	if _, err = requests.NewRequest(http.MethodGet, server.URL, nil); !errors.Is(err, MCSTErrors.ErrOffline) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDoRetry(t *testing.T) {
+	setNetwork(t, configs.Network{Retries: 2, RetryWait: "1ms"})
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requestCount++
+		if requestCount < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	req, err := requests.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := requests.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requestCount != 3 {
+		t.Fatalf("status = %s, 请求了 %d 次", resp.Status, requestCount)
+	}
+
+	// 重试次数用完后返回最后的响应
+	requestCount = -10
+	resp, err = requests.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || requestCount != -7 {
+		t.Fatalf("status = %s, 请求了 %d 次", resp.Status, requestCount+10)
+	}
+}
+
+func TestStreamReadTimeout(t *testing.T) {
+	setNetwork(t, configs.Network{Timeout: "200ms"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 发送响应头和一部分数据后不再发送
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	req, err := requests.NewRequest(http.MethodGet, server.URL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := requests.DoStream(req)
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer func() { _ = resp.Body.Close() }()
-	t.Log(resp.Status)
+	start := time.Now()
+	data, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, MCSTErrors.ErrReadTimeout) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrReadTimeout, err)
+	}
+	if string(data) != "partial" || time.Since(start) > 5*time.Second {
+		t.Fatalf("读取了 %q, 用了 %s", data, time.Since(start))
+	}
+}
+
+func TestMirrorFailover(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer mirror.Close()
+	setNetwork(t, configs.Network{Mirrors: map[string][]string{
+		primary.URL:         {"http://127.0.0.1:1", mirror.URL + "/mirror"},
+		primary.URL + "/v2": {mirror.URL + "/v2-mirror"},
+	}})
+
+	urls := requests.MirrorURLs(primary.URL + "/v2/file.json")
+	if len(urls) != 2 || urls[1] != mirror.URL+"/v2-mirror/file.json" {
+		t.Fatalf("urls = %v", urls)
+	}
+
+	req, err := requests.NewRequest(http.MethodGet, primary.URL+"/file.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := requests.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "/mirror/file.json" {
+		t.Fatalf("status = %s, body = %q", resp.Status, body)
+	}
+}
+
+func TestUnsupportedProxy(t *testing.T) {
+	setNetwork(t, configs.Network{Proxy: "ftp://127.0.0.1:21"})
+	req, err := requests.NewRequest(http.MethodGet, "http://127.0.0.1:1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = requests.Do(req); !errors.Is(err, MCSTErrors.ErrUnsupportedProxy) {
+		t.Fatalf("err = %v", err)
+	}
 }
@@ -0,0 +1,191 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package slp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// protocolVersion 握手时使用的协议版本, -1 表示不确定, 服务器会返回自己的版本
+const protocolVersion = -1
+
+// maxResponseSize 状态回复的最大长度
+const maxResponseSize = 1 << 20
+
+// Status Server List Ping 返回的服务器状态
+type Status struct {
+	Version struct {
+		Name     string `json:"name" yaml:"name"`
+		Protocol int    `json:"protocol" yaml:"protocol"`
+	} `json:"version" yaml:"version"`
+	Players struct {
+		Max    int `json:"max" yaml:"max"`
+		Online int `json:"online" yaml:"online"`
+	} `json:"players" yaml:"players"`
+	Description Description `json:"description" yaml:"description"`
+}
+
+// Description 服务器的 MOTD, 可以是字符串或聊天组件, 解析后只保留文本
+type Description string
+
+func (d *Description) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var b strings.Builder
+	chatText(&b, raw)
+	*d = Description(stripFormatting(b.String()))
+	return nil
+}
+
+// chatText 按顺序拼接聊天组件中的 text 和 extra
+func chatText(b *strings.Builder, component any) {
+	switch c := component.(type) {
+	case string:
+		b.WriteString(c)
+	case []any:
+		for _, child := range c {
+			chatText(b, child)
+		}
+	case map[string]any:
+		if text, ok := c["text"].(string); ok {
+			b.WriteString(text)
+		}
+		chatText(b, c["extra"])
+	}
+}
+
+// stripFormatting 删除 § 开头的格式代码
+func stripFormatting(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '§' {
+			i++
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
+
+// Ping 向 address 发送 Server List Ping 并返回服务器的状态, timeout 限制整个过程的时间
+func Ping(address string, timeout time.Duration) (Status, error) {
+	var status Status
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return status, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return status, err
+	}
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return status, err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	// 握手, next state 为 1 (status), 然后请求状态
+	var handshake bytes.Buffer
+	handshake.Write(varint(0x00))
+	handshake.Write(varint(protocolVersion))
+	handshake.Write(varint(len(host)))
+	handshake.WriteString(host)
+	_ = binary.Write(&handshake, binary.BigEndian, uint16(port))
+	handshake.Write(varint(1))
+	if err = writePacket(conn, handshake.Bytes()); err != nil {
+		return status, err
+	}
+	if err = writePacket(conn, varint(0x00)); err != nil {
+		return status, err
+	}
+
+	reader := bufio.NewReader(conn)
+	if _, err = readVarint(reader); err != nil {
+		return status, err
+	}
+	id, err := readVarint(reader)
+	if err != nil {
+		return status, err
+	}
+	if id != 0x00 {
+		return status, fmt.Errorf("%w: 数据包id %d", MCSTErrors.ErrBadPingResponse, id)
+	}
+	size, err := readVarint(reader)
+	if err != nil {
+		return status, err
+	}
+	if size < 0 || size > maxResponseSize {
+		return status, fmt.Errorf("%w: 长度 %d", MCSTErrors.ErrBadPingResponse, size)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(reader, data); err != nil {
+		return status, err
+	}
+	err = json.Unmarshal(data, &status)
+	return status, err
+}
+
+// writePacket 发送以长度开头的数据包
+func writePacket(w io.Writer, payload []byte) error {
+	_, err := w.Write(append(varint(len(payload)), payload...))
+	return err
+}
+
+// varint 使用 Minecraft 协议的 VarInt 编码, 负数按 32 位补码编码
+func varint(value int) []byte {
+	v := uint32(int32(value))
+	var buf []byte
+	for {
+		if v&^0x7F == 0 {
+			return append(buf, byte(v))
+		}
+		buf = append(buf, byte(v&0x7F|0x80))
+		v >>= 7
+	}
+}
+
+func readVarint(r io.ByteReader) (int, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int(int32(value)), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: VarInt 过长", MCSTErrors.ErrBadPingResponse)
+}
@@ -0,0 +1,94 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package slp_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/slp"
+)
+
+// readPacket 读取以 VarInt 长度开头的数据包
+func readPacket(r *bufio.Reader) ([]byte, error) {
+	var size, shift int
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size |= int(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	packet := make([]byte, size)
+	_, err := io.ReadFull(r, packet)
+	return packet, err
+}
+
+func varint(value int) []byte {
+	var buf []byte
+	for value >= 0x80 {
+		buf = append(buf, byte(value&0x7F|0x80))
+		value >>= 7
+	}
+	return append(buf, byte(value))
+}
+
+func TestPing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	response := `{"version":{"name":"1.21.1","protocol":767},"players":{"max":20,"online":3},` +
+		`"description":{"text":"§aHello ","extra":[{"text":"World"},"!"]}}`
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		// 握手和状态请求
+		for range 2 {
+			if _, err = readPacket(reader); err != nil {
+				return
+			}
+		}
+		payload := append(append([]byte{0x00}, varint(len(response))...), response...)
+		_, _ = conn.Write(append(varint(len(payload)), payload...))
+	}()
+
+	status, err := slp.Ping(listener.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Version.Name != "1.21.1" || status.Players.Online != 3 || status.Players.Max != 20 {
+		t.Fatalf("状态不正确: %+v", status)
+	}
+	if status.Description != "Hello World!" {
+		t.Fatalf("预期 MOTD %q, 实际为 %q", "Hello World!", status.Description)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package store
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+)
+
+// Path 返回 SHA-256 对应的文件在仓库中的位置: <StoreDir>/sha256/ab/abcdef...
+func Path(sha256 string) (string, error) {
+	if !validDigest(sha256) {
+		return "", fmt.Errorf("%w: %q", MCSTErrors.ErrInvalidSHA256, sha256)
+	}
+	sha256 = strings.ToLower(sha256)
+	return filepath.Join(configs.StoreDir, "sha256", sha256[:2], sha256), nil
+}
+
+// validDigest 检查是否为64位十六进制的 SHA-256
+func validDigest(sha256 string) bool {
+	if len(sha256) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(sha256)
+	return err == nil
+}
+
+// Has 检查仓库中是否已经有这个文件
+func Has(sha256 string) bool {
+	blob, err := Path(sha256)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(blob)
+	return err == nil
+}
+
+// Put 把已经校验过的文件移动到仓库中; 如果仓库中已经有相同的文件, 则删除 path
+func Put(path, sha256 string) (string, error) {
+	blob, err := Path(sha256)
+	if err != nil {
+		return "", err
+	}
+	if Has(sha256) {
+		if err := os.Remove(path); err != nil {
+			return "", err
+		}
+		return blob, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, blob); err != nil {
+		// 不在同一个文件系统上时无法直接移动
+		if err = copyFile(path, blob); err != nil {
+			return "", err
+		}
+		if err = os.Remove(path); err != nil {
+			return "", err
+		}
+	}
+	// 仓库中的文件可能被链接到多个服务器, 不允许修改
+	return blob, os.Chmod(blob, 0o555)
+}
+
+// Import 把文件复制到仓库中, 不会修改原文件
+func Import(path string) (string, download.Checksums, error) {
+	checksums, err := download.FileChecksums(path)
+	if err != nil {
+		return "", checksums, err
+	}
+	blob, err := Path(checksums.SHA256)
+	if err != nil {
+		return "", checksums, err
+	}
+	if Has(checksums.SHA256) {
+		return blob, checksums, nil
+	}
+	tmp := blob + ".tmp"
+	if err = os.MkdirAll(filepath.Dir(tmp), 0o755); err != nil {
+		return "", checksums, err
+	}
+	if err = copyFile(path, tmp); err != nil {
+		return "", checksums, err
+	}
+	blob, err = Put(tmp, checksums.SHA256)
+	return blob, checksums, err
+}
+
+// Link 把仓库中的文件放到 dst; 依次尝试 reflink, 硬链接和复制
+func Link(blob, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := reflink(blob, dst); err == nil {
+		return nil
+	}
+	if err := os.Link(blob, dst); err == nil {
+		return nil
+	}
+	return copyFile(blob, dst)
+}
+
+// Blobs 返回仓库中所有文件的 SHA-256, 忽略文件名不是 SHA-256 的文件
+func Blobs() ([]string, error) {
+	var blobs []string
+	root := filepath.Join(configs.StoreDir, "sha256")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && validDigest(d.Name()) {
+			blobs = append(blobs, d.Name())
+		}
+		return nil
+	})
+	return blobs, err
+}
+
+// GC 删除所有不在 referenced 中的文件, dryRun 为 true 时只返回将被删除的文件
+func GC(referenced map[string]bool, dryRun bool) ([]string, error) {
+	blobs, err := Blobs()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, blob := range blobs {
+		if referenced[blob] {
+			continue
+		}
+		removed = append(removed, blob)
+		if dryRun {
+			continue
+		}
+		path, err := Path(blob)
+		if err != nil {
+			return removed, err
+		}
+		if err = os.Remove(path); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// Verify 重新计算 sha256 对应文件的校验值
+func Verify(sha256 string) error {
+	blob, err := Path(sha256)
+	if err != nil {
+		return err
+	}
+	checksums, err := download.FileChecksums(blob)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(checksums.SHA256, sha256) {
+		return fmt.Errorf("%w: %s 实际为 %s", MCSTErrors.ErrChecksumMismatch, sha256, checksums.SHA256)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcFile.Close() }()
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dstFile, srcFile); err != nil {
+		_ = dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
@@ -0,0 +1,84 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package store_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/store"
+)
+
+func TestStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := configs.InitData(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paper.jar")
+	if err := os.WriteFile(path, []byte("paper"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 导入两次相同的文件只会保存一份
+	blob, checksums, err := store.Import(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := store.Import(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path, _ := store.Path(checksums.SHA256); blob != again || blob != path {
+		t.Fatalf("重复导入的路径不一致: %s %s", blob, again)
+	}
+	if err = store.Verify(checksums.SHA256); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "server.jar")
+	if err = store.Link(blob, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "paper" {
+		t.Fatalf("链接的文件内容错误: %q", data)
+	}
+
+	// 无效的 SHA-256 不会 panic
+	if _, err = store.Path("ab"); !errors.Is(err, MCSTErrors.ErrInvalidSHA256) {
+		t.Fatalf("err = %v", err)
+	}
+	if store.Has("zz") {
+		t.Fatal("无效的 SHA-256 不应该存在")
+	}
+
+	// 被引用的文件不会被清理
+	removed, err := store.GC(map[string]bool{checksums.SHA256: true}, false)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("不应该清理被引用的文件: %v %v", removed, err)
+	}
+	removed, err = store.GC(map[string]bool{}, false)
+	if err != nil || len(removed) != 1 || store.Has(checksums.SHA256) {
+		t.Fatalf("没有清理未被引用的文件: %v %v", removed, err)
+	}
+}
@@ -0,0 +1,295 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/rcon"
+	"github.com/apex/log"
+)
+
+// DetachKeys attach 时单独输入这一行会断开控制台, 服务器继续在后台运行
+const DetachKeys = "~."
+
+// rconTimeout 连接 RCON 和执行命令的超时时间
+const rconTimeout = 5 * time.Second
+
+// daemonReadyTimeout 等待自动启动的守护进程开始监听的最长时间
+const daemonReadyTimeout = 5 * time.Second
+
+// remoteErrors 守护进程返回的错误信息会还原为这些错误, 以便使用 errors.Is 判断
+var remoteErrors = []error{
+	MCSTErrors.ErrServerNotFound,
+	MCSTErrors.ErrServerRunning,
+	MCSTErrors.ErrServerNotRunning,
+}
+
+// Dial 连接守护进程
+func Dial() (net.Conn, error) {
+	conn, err := net.Dial("unix", SocketPath())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", MCSTErrors.ErrDaemonNotRunning, err)
+	}
+	return conn, nil
+}
+
+// call 发送请求并读取回复, 返回的 reader 用于继续读取连接中的数据
+func call(conn net.Conn, req Request) (*bufio.Reader, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		for _, remote := range remoteErrors {
+			if remote.Error() == resp.Error {
+				return nil, remote
+			}
+		}
+		return nil, errors.New(resp.Error)
+	}
+	return reader, nil
+}
+
+// Do 连接守护进程并发送一个请求
+func Do(req Request) error {
+	conn, err := Dial()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	_, err = call(conn, req)
+	return err
+}
+
+// EnsureDaemon 守护进程没有运行时在后台启动 'MCST daemon', 日志写入 run/daemon.log
+func EnsureDaemon(ctx context.Context) error {
+	if err := Do(Request{Action: ActionPing}); err == nil {
+		return nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(filepath.Join(configs.RunDir, "daemon.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logFile.Close() }()
+	daemon := exec.Command(executable, "daemon")
+	daemon.Stdout, daemon.Stderr = logFile, logFile
+	daemon.SysProcAttr = daemonSysProcAttr()
+	if err = daemon.Start(); err != nil {
+		return err
+	}
+	_ = daemon.Process.Release()
+
+	deadline := time.Now().Add(daemonReadyTimeout)
+	for {
+		if err = Do(Request{Action: ActionPing}); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %w", MCSTErrors.ErrDaemonNotReady, err)
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Attach 连接服务器的控制台: 服务器的输出写入 stdout, stdin 的每一行发送到服务器
+//
+// 输入 [DetachKeys], stdin 结束或 ctx 取消时断开, 服务器继续运行; 服务器退出后返回
+func Attach(ctx context.Context, name string, stdin io.Reader, stdout io.Writer) error {
+	conn, err := Dial()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	reader, err := call(conn, Request{Action: ActionAttach, Name: name})
+	if err != nil {
+		return err
+	}
+
+	var detached atomic.Bool
+	detach := func() {
+		detached.Store(true)
+		_ = conn.Close()
+	}
+	stop := context.AfterFunc(ctx, detach)
+	defer stop()
+	go func() {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			if line == DetachKeys {
+				break
+			}
+			if _, err := io.WriteString(conn, line+"\n"); err != nil {
+				return
+			}
+		}
+		detach()
+	}()
+
+	_, err = io.Copy(stdout, reader)
+	if detached.Load() {
+		log.WithField("name", name).Info("已断开控制台, 服务器仍在后台运行")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.WithField("name", name).Info("服务器已退出")
+	return nil
+}
+
+// Stop 停止服务器并等待它退出
+//
+// 由守护进程管理的服务器由守护进程停止; 在前台运行的服务器优先通过 RCON 发送停止命令, 否则向它的 'MCST start' 发送 SIGTERM;
+// timeout 内没有退出时向服务器发送 SIGTERM, 之后再发送 SIGKILL
+func Stop(ctx context.Context, config configs.Server, timeout time.Duration) error {
+	err := Do(Request{Action: ActionStop, Name: config.Name, Timeout: timeout})
+	if err == nil || !errors.Is(err, MCSTErrors.ErrServerNotRunning) && !errors.Is(err, MCSTErrors.ErrDaemonNotRunning) {
+		return err
+	}
+
+	state, err := LoadState(config.Name)
+	if err != nil || !state.Alive() {
+		return MCSTErrors.ErrServerNotRunning
+	}
+	// 记录原因, 'MCST start' 保存退出的状态时会使用它
+	previous := state.Reason
+	state.Reason = ReasonStop
+	if err = state.Save(); err != nil {
+		return err
+	}
+	if err = stopForeground(ctx, config, state, timeout); err != nil {
+		restoreReason(state, previous)
+	}
+	return err
+}
+
+// restoreReason 停止失败并且服务器仍在运行时恢复停止的原因, 否则之后服务器退出或崩溃会被当作主动停止, 不会自动重启
+func restoreReason(stopping State, previous string) {
+	state, err := LoadState(stopping.Name)
+	if err != nil || !state.Alive() || state.PID != stopping.PID || state.Reason != ReasonStop {
+		return
+	}
+	state.Reason = previous
+	if err = state.Save(); err != nil {
+		log.WithError(err).WithField("name", state.Name).Warn("无法保存服务器的状态")
+	}
+}
+
+// stopForeground 停止在前台运行的服务器: 通过 RCON 发送停止命令或中断 'MCST start', 超时后依次发送 SIGTERM 和 SIGKILL
+func stopForeground(ctx context.Context, config configs.Server, state State, timeout time.Duration) error {
+	defaultTimeout, killTimeout := config.Shutdown.Timeouts()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	logger := log.WithField("name", config.Name)
+	if client, err := rcon.DialServer(filepath.Join(configs.ServersDir, config.Name), rconTimeout); err == nil {
+		// 服务器停止时会关闭 RCON 连接, 所以不等待命令的结果
+		err = client.Send(config.Shutdown.StopCommand())
+		_ = client.Close()
+		if err != nil {
+			return err
+		}
+		logger.Info("已通过 RCON 发送停止命令")
+	} else {
+		logger.WithError(err).Debug("无法使用 RCON")
+		if err = interrupt(state.OwnerPID); err != nil {
+			_ = terminate(state.PID)
+		}
+		logger.Info("正在停止服务器")
+	}
+
+	if exited, err := waitExit(ctx, state.PID, timeout); exited || err != nil {
+		return err
+	}
+	logger.WithField("timeout", timeout).Warn("服务器没有在规定的时间内退出, 发送 SIGTERM")
+	_ = terminate(state.PID)
+	if exited, err := waitExit(ctx, state.PID, killTimeout); exited || err != nil {
+		return err
+	}
+	logger.WithField("timeout", killTimeout).Warn("服务器没有响应 SIGTERM, 发送 SIGKILL")
+	return kill(state.PID)
+}
+
+// Restart 重启服务器, 重启后服务器由守护进程管理
+func Restart(ctx context.Context, config configs.Server, timeout time.Duration) error {
+	if err := EnsureDaemon(ctx); err != nil {
+		return err
+	}
+	err := Do(Request{Action: ActionRestart, Server: &config, Timeout: timeout})
+	if !errors.Is(err, MCSTErrors.ErrServerRunning) {
+		return err
+	}
+	// 服务器在前台运行, 停止后交给守护进程
+	if err = Stop(ctx, config, timeout); err != nil {
+		return err
+	}
+	return Do(Request{Action: ActionStart, Server: &config})
+}
+
+// waitExit 等待进程退出, 超时返回 false
+func waitExit(ctx context.Context, pid int, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for pidExists(pid) {
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, context.Cause(ctx)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return true, nil
+}
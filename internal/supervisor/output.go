@@ -0,0 +1,97 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/Arama0517/MCST/internal/bytes"
+)
+
+// scrollbackSize attach 时发送的最近输出的大小
+const scrollbackSize = int(256 * bytes.KiB)
+
+// subscriberBuffer 每个客户端最多缓存的未发送的输出数量, 超过后断开这个客户端
+const subscriberBuffer = 256
+
+// Output 保存服务器最近的输出, 并转发给所有 attach 的客户端
+type Output struct {
+	mu sync.Mutex
+	// scrollback 最多保存 2*scrollbackSize 的输出, 超过后一次性丢弃旧的部分, 避免每次写入都复制
+	scrollback  []byte
+	subscribers map[chan []byte]struct{}
+	closed      bool
+}
+
+func NewOutput() *Output {
+	return &Output{subscribers: map[chan []byte]struct{}{}}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.scrollback = append(o.scrollback, p...)
+	if len(o.scrollback) > 2*scrollbackSize {
+		o.scrollback = append(o.scrollback[:0], o.scrollback[len(o.scrollback)-scrollbackSize:]...)
+	}
+	data := slices.Clone(p)
+	for ch := range o.subscribers {
+		select {
+		case ch <- data:
+		default:
+			// 客户端太慢, 断开它而不是阻塞服务器的输出
+			delete(o.subscribers, ch)
+			close(ch)
+		}
+	}
+	return len(p), nil
+}
+
+// Subscribe 返回最近的输出和接收之后的输出的通道; 服务器退出或调用 cancel 后通道会被关闭
+func (o *Output) Subscribe() (scrollback []byte, output <-chan []byte, cancel func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ch := make(chan []byte, subscriberBuffer)
+	if o.closed {
+		close(ch)
+	} else {
+		o.subscribers[ch] = struct{}{}
+	}
+	scrollback = o.scrollback[max(len(o.scrollback)-scrollbackSize, 0):]
+	return slices.Clone(scrollback), ch, func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if _, ok := o.subscribers[ch]; ok {
+			delete(o.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close 关闭所有订阅, 服务器退出后调用
+func (o *Output) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	for ch := range o.subscribers {
+		delete(o.subscribers, ch)
+		close(ch)
+	}
+}
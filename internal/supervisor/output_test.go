@@ -0,0 +1,50 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor_test
+
+import (
+	stdbytes "bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/bytes"
+	"github.com/Arama0517/MCST/internal/supervisor"
+)
+
+func TestOutputScrollback(t *testing.T) {
+	output := supervisor.NewOutput()
+	var written []byte
+	for i := 0; len(written) < int(bytes.MiB); i++ {
+		line := []byte(fmt.Sprintf("[%08d] server output\n", i))
+		if _, err := output.Write(line); err != nil {
+			t.Fatal(err)
+		}
+		written = append(written, line...)
+
+		// 丢弃旧输出的前后都只返回最近的 256 KiB
+		if i%5000 == 0 || len(written) >= int(bytes.MiB) {
+			scrollback, _, cancel := output.Subscribe()
+			cancel()
+			want := written[max(len(written)-int(256*bytes.KiB), 0):]
+			if !stdbytes.Equal(scrollback, want) {
+				t.Fatalf("写入 %d 字节后 scrollback 为 %d 字节, 应该为最后的 %d 字节", len(written), len(scrollback), len(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/apex/log"
+	"github.com/go-cmd/cmd"
+)
+
+// Process 一个由 MCST 启动的服务器进程, 启动和退出时会更新服务器的状态文件
+type Process struct {
+	Name      string
+	StartTime time.Time
+	PID       int
+	config    configs.Server
+	cmd       *cmd.Cmd
+	console   *os.File // 服务器标准输入的写入端
+	reports   []string // 启动时已有的崩溃报告
+	done      chan struct{}
+	status    cmd.Status
+
+	mu     sync.Mutex
+	reason string
+}
+
+// Start 在服务器目录中启动服务器, 服务器的输出原样写入 stdout 和 stderr; 服务器已经在运行时返回 ErrServerRunning
+func Start(config configs.Server, stdout, stderr io.Writer) (*Process, error) {
+	if state, err := LoadState(config.Name); err == nil && state.Alive() {
+		return nil, MCSTErrors.ErrServerRunning
+	}
+	name, args, err := config.Command()
+	if err != nil {
+		return nil, err
+	}
+	// 使用管道作为服务器的标准输入, 服务器退出时不需要等待输入的下一行
+	stdin, console, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	javaCmd := cmd.NewCmdOptions(cmd.Options{
+		BeforeExec: []func(*exec.Cmd){func(c *exec.Cmd) {
+			c.Stdout, c.Stderr = stdout, stderr
+		}},
+	}, name, args...)
+	javaCmd.Dir = filepath.Join(configs.ServersDir, config.Name)
+	p := &Process{
+		Name:      config.Name,
+		StartTime: time.Now(),
+		config:    config,
+		cmd:       javaCmd,
+		console:   console,
+		reports:   crashReports(config.Name),
+		done:      make(chan struct{}),
+	}
+	statusChan := javaCmd.StartWithStdin(stdin)
+
+	// go-cmd 在进程启动后才会设置 PID
+	for p.PID == 0 {
+		select {
+		case p.status = <-statusChan:
+			_ = stdin.Close()
+			_ = console.Close()
+			if p.status.Error != nil && p.status.PID == 0 {
+				return nil, p.status.Error
+			}
+			p.PID = p.status.PID
+			statusChan = nil
+		case <-time.After(10 * time.Millisecond):
+			p.PID = javaCmd.Status().PID
+		}
+	}
+	if err = p.state().Save(); err != nil {
+		log.WithError(err).Warn("无法保存服务器的状态")
+	}
+	go func() {
+		if statusChan != nil {
+			p.status = <-statusChan
+			_ = stdin.Close()
+			_ = console.Close()
+		}
+		if err := p.exitState().Save(); err != nil {
+			log.WithError(err).Warn("无法保存服务器的状态")
+		}
+		close(p.done)
+	}()
+	return p, nil
+}
+
+func (p *Process) state() State {
+	return State{
+		Name:      p.Name,
+		Running:   true,
+		PID:       p.PID,
+		OwnerPID:  os.Getpid(),
+		StartTime: p.StartTime,
+	}
+}
+
+// exitState 返回退出后的状态; 其他进程请求停止时写入的原因优先
+func (p *Process) exitState() State {
+	state := p.state()
+	state.Running = false
+	state.StopTime = time.Now()
+	state.ExitCode = p.status.Exit
+	for _, report := range crashReports(p.Name) {
+		if !slices.Contains(p.reports, report) {
+			state.CrashReports = append(state.CrashReports, report)
+		}
+	}
+	p.mu.Lock()
+	state.Reason = p.reason
+	p.mu.Unlock()
+	if previous, err := LoadState(p.Name); err == nil && previous.Reason != "" && previous.StartTime.Equal(p.StartTime) {
+		state.Reason = previous.Reason
+	}
+	if state.Reason == "" {
+		state.Reason = ReasonExit
+		if state.ExitCode != 0 || p.status.Error != nil {
+			state.Reason = ReasonCrash
+		}
+	}
+	return state
+}
+
+// Console 返回服务器的控制台, 写入的每一行都是一条命令
+func (p *Process) Console() io.Writer {
+	return p.console
+}
+
+// Done 服务器退出并保存状态后关闭
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait 等待服务器退出并返回退出代码; 服务器被信号终止时返回错误
+func (p *Process) Wait() (int, error) {
+	<-p.done
+	return p.status.Exit, p.status.Error
+}
+
+// Shutdown 在控制台输入停止命令并等待服务器退出; timeout 内没有退出时向进程组发送 SIGTERM, 再等待一段时间后发送 SIGKILL
+//
+// timeout 为 0 时使用服务器的设置, reason 会记录到服务器的状态中
+func (p *Process) Shutdown(reason string, timeout time.Duration) {
+	p.mu.Lock()
+	if p.reason == "" {
+		p.reason = reason
+	}
+	p.mu.Unlock()
+	defaultTimeout, killTimeout := p.config.Shutdown.Timeouts()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	logger := log.WithField("name", p.Name)
+
+	logger.Info("正在停止服务器")
+	_, _ = io.WriteString(p.console, p.config.Shutdown.StopCommand()+"\n")
+	if p.waitFor(timeout) {
+		return
+	}
+	logger.WithField("timeout", timeout).Warn("服务器没有在规定的时间内退出, 发送 SIGTERM")
+	_ = terminate(p.PID)
+	if p.waitFor(killTimeout) {
+		return
+	}
+	logger.WithField("timeout", killTimeout).Warn("服务器没有响应 SIGTERM, 发送 SIGKILL")
+	_ = kill(p.PID)
+	<-p.done
+}
+
+// waitFor 等待服务器退出, 超时返回 false
+func (p *Process) waitFor(timeout time.Duration) bool {
+	select {
+	case <-p.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// ForwardLines 把 src 中的每一行写入 dst, 只转发完整的行, 直到 src 结束或写入失败
+func ForwardLines(dst io.Writer, src io.Reader) {
+	scanner := bufio.NewScanner(src)
+	for scanner.Scan() {
+		if _, err := io.WriteString(dst, strings.TrimSuffix(scanner.Text(), "\r")+"\n"); err != nil {
+			return
+		}
+	}
+}
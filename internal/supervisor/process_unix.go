@@ -0,0 +1,43 @@
+//go:build unix
+
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import "syscall"
+
+// daemonSysProcAttr 让守护进程使用新的会话, 关闭终端或断开 SSH 后不会收到 SIGHUP
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// terminate 向服务器的进程组发送 SIGTERM; go-cmd 启动的进程是自己的进程组的组长
+func terminate(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+// kill 向服务器的进程组发送 SIGKILL
+func kill(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// interrupt 向前台运行服务器的 'MCST start' 发送 SIGTERM, 它会自己停止服务器
+func interrupt(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
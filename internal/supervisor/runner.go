@@ -0,0 +1,195 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/apex/log"
+)
+
+// Runner 运行服务器, 并按照服务器的自动重启策略在它退出后重新启动
+type Runner struct {
+	Name   string
+	config configs.Server
+	stdout io.Writer
+	stderr io.Writer
+
+	mu       sync.Mutex
+	process  *Process
+	stopping chan struct{} // Shutdown 后关闭, 不再重启
+	stopOnce sync.Once
+	reason   string // Shutdown 的参数
+	timeout  time.Duration
+	done     chan struct{}
+	exit     int
+	err      error
+}
+
+// Run 启动服务器, 之后服务器的输出都写入 stdout 和 stderr; 第一次启动失败时返回错误
+func Run(config configs.Server, stdout, stderr io.Writer) (*Runner, error) {
+	process, err := Start(config, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Runner{
+		Name:     config.Name,
+		config:   config,
+		stdout:   stdout,
+		stderr:   stderr,
+		process:  process,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go r.loop()
+	return r, nil
+}
+
+func (r *Runner) loop() {
+	defer close(r.done)
+	backoff, maxBackoff, window, maxRestarts := r.config.Restart.Limits()
+	var restarts []time.Time
+	process := r.current()
+	for {
+		r.exit, r.err = process.Wait()
+		state, err := LoadState(r.Name)
+		if err != nil {
+			return
+		}
+		logger := log.WithFields(log.Fields{"name": r.Name, "exit": state.ExitCode, "reason": state.Reason})
+		if len(state.CrashReports) > 0 {
+			logger = logger.WithField("crash_reports", state.CrashReports)
+		}
+		if state.Reason == ReasonCrash {
+			logger.WithError(r.err).Error("服务器崩溃了")
+		}
+		// 只有服务器自己退出时才重启, 'MCST stop' 等主动停止的不重启
+		if state.Reason != ReasonExit && state.Reason != ReasonCrash ||
+			!r.config.Restart.ShouldRestart(state.Reason == ReasonCrash) || r.stopped() {
+			return
+		}
+
+		now := time.Now()
+		restarts = pruneBefore(restarts, now.Add(-window))
+		if len(restarts) >= maxRestarts {
+			logger.WithField("window", window).Errorf("服务器在 %s 内重启了 %d 次, 已停止自动重启", window, len(restarts))
+			state.Parked = true
+			if err = state.Save(); err != nil {
+				logger.WithError(err).Warn("无法保存服务器的状态")
+			}
+			return
+		}
+		delay := restartDelay(backoff, maxBackoff, len(restarts))
+		logger.WithField("delay", delay).Warn("服务器将自动重启")
+		select {
+		case <-time.After(delay):
+		case <-r.stopping:
+			return
+		}
+		restarts = append(restarts, time.Now())
+
+		if process, err = Start(r.config, r.stdout, r.stderr); err != nil {
+			logger.WithError(err).Error("无法重启服务器")
+			r.err = err
+			return
+		}
+		r.mu.Lock()
+		r.process = process
+		r.mu.Unlock()
+		// 启动期间调用了 Shutdown
+		if r.stopped() {
+			process.Shutdown(r.reason, r.timeout)
+		}
+	}
+}
+
+// restartDelay 返回第 n+1 次重启前等待的时间, 每次翻倍, 不超过 maxBackoff
+func restartDelay(backoff, maxBackoff time.Duration, n int) time.Duration {
+	delay := min(backoff, maxBackoff)
+	for ; n > 0 && delay < maxBackoff; n-- {
+		delay = min(delay*2, maxBackoff)
+	}
+	return delay
+}
+
+// pruneBefore 删除 since 之前的时间
+func pruneBefore(times []time.Time, since time.Time) []time.Time {
+	for len(times) > 0 && times[0].Before(since) {
+		times = times[1:]
+	}
+	return times
+}
+
+func (r *Runner) current() *Process {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.process
+}
+
+func (r *Runner) stopped() bool {
+	select {
+	case <-r.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Console 返回服务器的控制台; 服务器等待重启时输入的命令会被丢弃
+func (r *Runner) Console() io.Writer {
+	return consoleWriter{r}
+}
+
+type consoleWriter struct {
+	r *Runner
+}
+
+func (w consoleWriter) Write(p []byte) (int, error) {
+	if _, err := w.r.current().Console().Write(p); err != nil {
+		log.WithField("name", w.r.Name).Debug("服务器没有运行, 已丢弃输入的命令")
+	}
+	return len(p), nil
+}
+
+// Shutdown 停止服务器并不再自动重启, 参数与 [Process.Shutdown] 相同
+func (r *Runner) Shutdown(reason string, timeout time.Duration) {
+	r.mu.Lock()
+	r.stopOnce.Do(func() {
+		r.reason, r.timeout = reason, timeout
+		close(r.stopping)
+	})
+	process := r.process
+	r.mu.Unlock()
+	process.Shutdown(reason, timeout)
+	<-r.done
+}
+
+// Done 服务器退出并且不再重启后关闭
+func (r *Runner) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait 等待服务器不再重启, 返回最后一次的退出代码
+func (r *Runner) Wait() (int, error) {
+	<-r.done
+	return r.exit, r.err
+}
@@ -0,0 +1,96 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/supervisor"
+)
+
+func TestCrashLoop(t *testing.T) {
+	useTempDirs(t)
+	// 每次运行都写入一个崩溃报告并以 1 退出
+	config := newServer(t, "crashing", `echo run >> runs; mkdir -p crash-reports; echo crash > crash-reports/crash-$$.txt; exit 1`)
+	config.Restart = configs.Restart{Policy: configs.RestartOnFailure, Backoff: "10ms", MaxBackoff: "20ms", MaxRestarts: 2, Window: "1m"}
+	runner, err := supervisor.Run(*config, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-runner.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("服务器一直在重启")
+	}
+
+	runs, err := os.ReadFile(filepath.Join(configs.ServersDir, "crashing", "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(runs), "run"); count != 3 {
+		t.Fatalf("预期运行 3 次, 实际运行了 %d 次", count)
+	}
+	state, err := supervisor.LoadState("crashing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Parked || state.Reason != supervisor.ReasonCrash || state.ExitCode != 1 || len(state.CrashReports) != 1 {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+}
+
+func TestRestartAlways(t *testing.T) {
+	useTempDirs(t)
+	config := newServer(t, "always", `echo run >> runs; while read line; do [ "$line" = stop ] && exit 0; done`)
+	config.Restart = configs.Restart{Policy: configs.RestartAlways, Backoff: "10ms"}
+	runner, err := supervisor.Run(*config, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 在控制台输入 stop 是服务器自己退出, 会被重启; 'MCST stop' 不会
+	if _, err = io.WriteString(runner.Console(), "stop\n"); err != nil {
+		t.Fatal(err)
+	}
+	runsPath := filepath.Join(configs.ServersDir, "always", "runs")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if runs, _ := os.ReadFile(runsPath); strings.Count(string(runs), "run") == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("服务器没有重启")
+		}
+	}
+	runner.Shutdown(supervisor.ReasonStop, 0)
+	if exit, err := runner.Wait(); exit != 0 || err != nil {
+		t.Fatalf("退出代码 %d, 错误 %v", exit, err)
+	}
+	state, err := supervisor.LoadState("always")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Running || state.Reason != supervisor.ReasonStop {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// 服务器停止的原因
+const (
+	ReasonStop    = "stop"    // 'MCST stop'
+	ReasonRestart = "restart" // 'MCST restart'
+	ReasonSignal  = "signal"  // 前台运行的 'MCST start' 收到了 Ctrl+C 或 SIGTERM
+	ReasonDaemon  = "daemon"  // 守护进程停止
+	ReasonExit    = "exit"    // 服务器自己正常退出, 例如在控制台输入了 stop
+	ReasonCrash   = "crash"   // 服务器的退出代码不为 0
+)
+
+// State 服务器的运行状态, 保存在 run/<name>.json
+type State struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	PID       int       `json:"pid"`       // 服务器的进程id
+	OwnerPID  int       `json:"owner_pid"` // 启动服务器的 MCST 进程id, 即守护进程或前台的 'MCST start'
+	StartTime time.Time `json:"start_time"`
+	StopTime  time.Time `json:"stop_time"`
+	ExitCode  int       `json:"exit_code"`
+	Reason    string    `json:"reason,omitempty"` // 停止的原因; 服务器运行时不为空表示已经请求停止
+	// CrashReports 服务器运行期间 crash-reports 中新增的文件
+	CrashReports []string `json:"crash_reports,omitempty"`
+	// Parked 服务器反复崩溃, 已经停止自动重启
+	Parked bool `json:"parked,omitempty"`
+}
+
+// StatePath 返回服务器状态文件的路径
+func StatePath(name string) string {
+	return filepath.Join(configs.RunDir, name+".json")
+}
+
+// LoadState 读取服务器的状态, 服务器从未启动过时返回 os.ErrNotExist
+func LoadState(name string) (State, error) {
+	var state State
+	data, err := os.ReadFile(StatePath(name))
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// Save 保存状态, 先写入临时文件再重命名, 避免其他进程读到不完整的文件
+func (s State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := StatePath(s.Name)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Alive 判断服务器是否仍在运行; MCST 被强制结束时状态文件不会更新, 所以还要检查进程是否存在
+func (s State) Alive() bool {
+	return s.Running && pidExists(s.PID)
+}
+
+func pidExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	exists, err := process.PidExists(int32(pid))
+	return err == nil && exists
+}
+
+// crashReports 返回服务器目录的 crash-reports 中的所有文件
+func crashReports(name string) []string {
+	dir := filepath.Join(configs.ServersDir, name, "crash-reports")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var reports []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			reports = append(reports, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return reports
+}
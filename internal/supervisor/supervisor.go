@@ -0,0 +1,261 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/apex/log"
+)
+
+// 客户端可以请求的操作
+const (
+	ActionPing    = "ping"
+	ActionStart   = "start"
+	ActionAttach  = "attach"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+)
+
+// Request 客户端发送给守护进程的请求, 占一行 JSON
+type Request struct {
+	Action  string          `json:"action"`
+	Name    string          `json:"name,omitempty"`
+	Server  *configs.Server `json:"server,omitempty"`  // start 和 restart 使用的服务器配置, 守护进程不会重新读取配置文件
+	Timeout time.Duration   `json:"timeout,omitempty"` // stop 和 restart 等待服务器退出的时间, 为 0 时使用服务器的设置
+}
+
+// Response 守护进程的回复, 占一行 JSON; attach 成功后连接会变为服务器的控制台
+type Response struct {
+	Error string `json:"error,omitempty"`
+}
+
+// SocketPath 守护进程监听的 Unix 套接字
+func SocketPath() string {
+	return filepath.Join(configs.RunDir, "supervisor.sock")
+}
+
+// Listen 监听 [SocketPath]; 已有守护进程在运行时返回 ErrDaemonRunning, 上次异常退出留下的套接字会被删除
+func Listen() (net.Listener, error) {
+	path := SocketPath()
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, MCSTErrors.ErrDaemonRunning
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// Supervisor 守护进程, 管理在后台运行的服务器
+type Supervisor struct {
+	mu      sync.Mutex
+	servers map[string]*server
+}
+
+// server 守护进程中运行的服务器
+type server struct {
+	*Runner
+	output *Output
+}
+
+func New() *Supervisor {
+	return &Supervisor{servers: map[string]*server{}}
+}
+
+// Serve 处理 listener 上的请求; ctx 取消后停止所有服务器, 等待它们退出后返回
+func (s *Supervisor) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				s.stopAll()
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Supervisor) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+	var req Request
+	if err = json.Unmarshal(line, &req); err != nil {
+		_ = writeResponse(conn, err)
+		return
+	}
+	switch req.Action {
+	case ActionPing:
+		_ = writeResponse(conn, nil)
+	case ActionStart:
+		if req.Server == nil {
+			_ = writeResponse(conn, MCSTErrors.ErrServerNotFound)
+			return
+		}
+		_ = writeResponse(conn, s.start(*req.Server))
+	case ActionAttach:
+		s.attach(conn, reader, req.Name)
+	case ActionStop:
+		_ = writeResponse(conn, s.stop(req.Name, ReasonStop, req.Timeout))
+	case ActionRestart:
+		if req.Server == nil {
+			_ = writeResponse(conn, MCSTErrors.ErrServerNotFound)
+			return
+		}
+		_ = writeResponse(conn, s.restart(*req.Server, req.Timeout))
+	default:
+		_ = writeResponse(conn, fmt.Errorf("%w: %s", MCSTErrors.ErrUnknownAction, req.Action))
+	}
+}
+
+func (s *Supervisor) start(config configs.Server) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.servers[config.Name]; ok {
+		return MCSTErrors.ErrServerRunning
+	}
+	output := NewOutput()
+	runner, err := Run(config, output, output)
+	if err != nil {
+		return err
+	}
+	srv := &server{Runner: runner, output: output}
+	s.servers[config.Name] = srv
+	log.WithField("name", config.Name).Info("服务器已启动")
+	go func() {
+		exit, err := runner.Wait()
+		output.Close()
+		s.remove(srv)
+		log.WithFields(log.Fields{"name": config.Name, "exit": exit}).WithError(err).Info("服务器已退出")
+	}()
+	return nil
+}
+
+// remove 从列表中移除已经退出的服务器, 同名的服务器可能已经重新启动
+func (s *Supervisor) remove(srv *server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.servers[srv.Name] == srv {
+		delete(s.servers, srv.Name)
+	}
+}
+
+// stop 停止服务器并等待它退出
+func (s *Supervisor) stop(name, reason string, timeout time.Duration) error {
+	s.mu.Lock()
+	srv, ok := s.servers[name]
+	s.mu.Unlock()
+	if !ok {
+		return MCSTErrors.ErrServerNotRunning
+	}
+	srv.Shutdown(reason, timeout)
+	s.remove(srv)
+	return nil
+}
+
+// restart 停止服务器后使用新的配置启动; 服务器在前台运行时返回 ErrServerRunning
+func (s *Supervisor) restart(config configs.Server, timeout time.Duration) error {
+	if err := s.stop(config.Name, ReasonRestart, timeout); err != nil && !errors.Is(err, MCSTErrors.ErrServerNotRunning) {
+		return err
+	}
+	return s.start(config)
+}
+
+// attach 把连接作为服务器的控制台: 先发送最近的输出, 之后转发服务器的输出和客户端输入的每一行
+func (s *Supervisor) attach(conn net.Conn, reader *bufio.Reader, name string) {
+	s.mu.Lock()
+	srv, ok := s.servers[name]
+	s.mu.Unlock()
+	if !ok {
+		_ = writeResponse(conn, MCSTErrors.ErrServerNotRunning)
+		return
+	}
+	if err := writeResponse(conn, nil); err != nil {
+		return
+	}
+	scrollback, output, cancel := srv.output.Subscribe()
+	defer cancel()
+	go func() {
+		// 客户端断开后不再转发输出
+		ForwardLines(srv.Console(), reader)
+		cancel()
+	}()
+	if _, err := conn.Write(scrollback); err != nil {
+		return
+	}
+	for data := range output {
+		if _, err := conn.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+// stopAll 停止所有服务器并等待它们退出
+func (s *Supervisor) stopAll() {
+	s.mu.Lock()
+	servers := make([]*server, 0, len(s.servers))
+	for _, srv := range s.servers {
+		servers = append(servers, srv)
+	}
+	s.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.Shutdown(ReasonDaemon, 0)
+		}()
+	}
+	wg.Wait()
+}
+
+func writeResponse(conn net.Conn, err error) error {
+	var resp Response
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,251 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package supervisor_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/supervisor"
+)
+
+func newServer(t *testing.T, name, script string) *configs.Server {
+	t.Helper()
+	if err := os.Mkdir(filepath.Join(configs.ServersDir, name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return &configs.Server{
+		Name:       name,
+		ServerArgs: []string{"-c", script},
+		Launch:     configs.Launch{Mode: configs.LaunchExecutable, Executable: "sh"},
+		Shutdown:   configs.Shutdown{Timeout: "1s", KillTimeout: "1s"},
+	}
+}
+
+// useTempDirs 让测试使用临时的运行目录和服务器目录
+func useTempDirs(t *testing.T) {
+	t.Helper()
+	runDir, serversDir := configs.RunDir, configs.ServersDir
+	t.Cleanup(func() { configs.RunDir, configs.ServersDir = runDir, serversDir })
+	configs.RunDir, configs.ServersDir = t.TempDir(), t.TempDir()
+}
+
+func TestSupervisor(t *testing.T) {
+	useTempDirs(t)
+	listener, err := supervisor.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = supervisor.Listen(); !errors.Is(err, MCSTErrors.ErrDaemonRunning) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrDaemonRunning, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- supervisor.New().Serve(ctx, listener) }()
+
+	console := newServer(t, "console", `echo ready; read line; echo "> $line"`)
+	if err = supervisor.Do(supervisor.Request{Action: supervisor.ActionStart, Server: console}); err != nil {
+		t.Fatal(err)
+	}
+	if err = supervisor.Do(supervisor.Request{Action: supervisor.ActionStart, Server: console}); !errors.Is(err, MCSTErrors.ErrServerRunning) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrServerRunning, err)
+	}
+
+	// 服务器退出后 Attach 返回, 输出包括 attach 之前的部分
+	stdin, stdinWriter := io.Pipe()
+	defer func() { _ = stdinWriter.Close() }()
+	go func() { _, _ = io.WriteString(stdinWriter, "hello\n") }()
+	var output strings.Builder
+	if err = supervisor.Attach(context.Background(), "console", stdin, &output); err != nil {
+		t.Fatal(err)
+	}
+	if output.String() != "ready\n> hello\n" {
+		t.Fatalf("输出不正确: %q", output.String())
+	}
+	if err = supervisor.Attach(context.Background(), "missing", stdin, io.Discard); !errors.Is(err, MCSTErrors.ErrServerNotRunning) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrServerNotRunning, err)
+	}
+
+	// 停止守护进程时停止所有服务器
+	if err = supervisor.Do(supervisor.Request{Action: supervisor.ActionStart, Server: newServer(t, "idle", "sleep 60")}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("守护进程没有停止")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	useTempDirs(t)
+
+	// 服务器收到停止命令后正常退出
+	config := newServer(t, "graceful", `while read line; do [ "$line" = stop ] && exit 0; done`)
+	process, err := supervisor.Start(*config, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = supervisor.Start(*config, io.Discard, io.Discard); !errors.Is(err, MCSTErrors.ErrServerRunning) {
+		t.Fatalf("预期 %v, 实际为 %v", MCSTErrors.ErrServerRunning, err)
+	}
+	process.Shutdown(supervisor.ReasonStop, 0)
+	state, err := supervisor.LoadState("graceful")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Running || state.ExitCode != 0 || state.Reason != supervisor.ReasonStop {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+
+	// 服务器忽略停止命令和 SIGTERM 时发送 SIGKILL
+	config = newServer(t, "stuck", `trap "" TERM; while :; do sleep 0.1; done`)
+	config.Shutdown = configs.Shutdown{Timeout: "100ms", KillTimeout: "100ms"}
+	if process, err = supervisor.Start(*config, io.Discard, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	process.Shutdown(supervisor.ReasonRestart, 0)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("停止服务器用了 %s", elapsed)
+	}
+	if state, err = supervisor.LoadState("stuck"); err != nil {
+		t.Fatal(err)
+	}
+	if state.Running || state.Reason != supervisor.ReasonRestart {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+}
+
+func TestStopRestoresReason(t *testing.T) {
+	useTempDirs(t)
+	config := newServer(t, "foreground", "")
+
+	// 模拟忽略 SIGTERM 的前台 'MCST start'
+	cmd := exec.Command("sh", "-c", `trap "" TERM; while :; do sleep 0.1; done`)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	state := supervisor.State{Name: config.Name, Running: true, PID: cmd.Process.Pid, OwnerPID: cmd.Process.Pid, StartTime: time.Now()}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止被取消后服务器仍在运行, 之后的退出不应该被当作主动停止
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := supervisor.Stop(ctx, *config, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("预期 %v, 实际为 %v", context.Canceled, err)
+	}
+	state, err := supervisor.LoadState(config.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Running || state.Reason != "" {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+}
+
+func TestStopOverRCON(t *testing.T) {
+	useTempDirs(t)
+	config := newServer(t, "rcon", "")
+	cmd := exec.Command("sh", "-c", `trap "" TERM; while :; do sleep 0.1; done`)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-exited
+	})
+	state := supervisor.State{Name: config.Name, Running: true, PID: cmd.Process.Pid, OwnerPID: cmd.Process.Pid, StartTime: time.Now()}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟 Minecraft: 收到 stop 后不回复, 关闭 RCON 连接并退出
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		for {
+			var size, id, packetType int32
+			if err = binary.Read(conn, binary.LittleEndian, &size); err != nil {
+				return
+			}
+			_ = binary.Read(conn, binary.LittleEndian, &id)
+			_ = binary.Read(conn, binary.LittleEndian, &packetType)
+			if _, err = io.CopyN(io.Discard, conn, int64(size-8)); err != nil {
+				return
+			}
+			if packetType == 2 {
+				_ = cmd.Process.Kill()
+				<-exited
+				return
+			}
+			_ = binary.Write(conn, binary.LittleEndian, []int32{10, id, 2})
+			_, _ = conn.Write([]byte{0, 0})
+		}
+	}()
+	properties := fmt.Sprintf("enable-rcon=true\nrcon.port=%d\nrcon.password=secret\n", listener.Addr().(*net.TCPAddr).Port)
+	if err = os.WriteFile(filepath.Join(configs.ServersDir, config.Name, "server.properties"), []byte(properties), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = supervisor.Stop(context.Background(), *config, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if state, err = supervisor.LoadState(config.Name); err != nil {
+		t.Fatal(err)
+	}
+	if state.Reason != supervisor.ReasonStop {
+		t.Fatalf("状态不正确: %+v", state)
+	}
+}
@@ -19,14 +19,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/Arama0517/MCST/internal/bytes"
 	"github.com/Arama0517/MCST/internal/configs"
 	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 	"github.com/Arama0517/MCST/internal/locale"
-	"github.com/apex/log"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -40,6 +42,9 @@ type configCmdFlags struct {
 	java       string
 	jvmArgs    []string
 	serverArgs []string
+	launch     configs.Launch
+	shutdown   configs.Shutdown
+	restart    configs.Restart
 	delete     bool // 如果为true就删除服务器
 }
 
@@ -57,7 +62,7 @@ func newConfigCmd() *cobra.Command {
 			isCheckConfig := true
 			cmdFlags := cmd.Flags()
 			cmdFlags.VisitAll(func(flag *pflag.Flag) {
-				if flag.Changed {
+				if flag.Changed && flag.Name != "name" {
 					isCheckConfig = false
 				}
 			})
@@ -66,19 +71,14 @@ func newConfigCmd() *cobra.Command {
 				return MCSTErrors.ErrServerNotFound
 			}
 			if isCheckConfig {
-				result := make(map[string]any)
-				structToMap(config, "", result)
-				for k, v := range result {
-					log.WithField("value", v).Info(k)
-				}
-				return nil
+				return printOutput(cmd.OutOrStdout(), config, keyValueTable(config, nil))
 			}
 			if flags.delete {
 				delete(configs.Configs.Servers, flags.name)
 				if err := os.RemoveAll(filepath.Join(configs.ServersDir, flags.name)); err != nil {
 					return err
 				}
-				return nil
+				return configs.Configs.Save()
 			}
 			memInfo, err := mem.VirtualMemory()
 			if err != nil {
@@ -127,9 +127,77 @@ func newConfigCmd() *cobra.Command {
 				config.Java.Args = flags.jvmArgs
 			}
 			if cmdFlags.Changed("server_args") {
-				config.Java.Args = flags.serverArgs
+				config.ServerArgs = flags.serverArgs
+			}
+			if cmdFlags.Changed("launch_mode") {
+				if !slices.Contains(configs.LaunchModes, flags.launch.Mode) {
+					return fmt.Errorf("%w: %q", MCSTErrors.ErrUnknownLaunchMode, flags.launch.Mode)
+				}
+				config.Launch.Mode = flags.launch.Mode
+			}
+			if cmdFlags.Changed("jar") {
+				config.Launch.Jar = flags.launch.Jar
+			}
+			if cmdFlags.Changed("main_class") {
+				config.Launch.MainClass = flags.launch.MainClass
+			}
+			if cmdFlags.Changed("classpath") {
+				config.Launch.Classpath = flags.launch.Classpath
+			}
+			if cmdFlags.Changed("arg_files") {
+				config.Launch.ArgFiles = flags.launch.ArgFiles
+			}
+			if cmdFlags.Changed("executable") {
+				config.Launch.Executable = flags.launch.Executable
+			}
+			if cmdFlags.Changed("stop_command") {
+				config.Shutdown.Command = flags.shutdown.Command
+			}
+			if cmdFlags.Changed("stop_timeout") {
+				if _, err := time.ParseDuration(flags.shutdown.Timeout); err != nil {
+					return err
+				}
+				config.Shutdown.Timeout = flags.shutdown.Timeout
+			}
+			if cmdFlags.Changed("kill_timeout") {
+				if _, err := time.ParseDuration(flags.shutdown.KillTimeout); err != nil {
+					return err
+				}
+				config.Shutdown.KillTimeout = flags.shutdown.KillTimeout
+			}
+			if cmdFlags.Changed("restart") {
+				if !slices.Contains(configs.RestartPolicies, flags.restart.Policy) {
+					return fmt.Errorf("%w: %q", MCSTErrors.ErrUnknownRestart, flags.restart.Policy)
+				}
+				config.Restart.Policy = flags.restart.Policy
+			}
+			if cmdFlags.Changed("backoff") {
+				if _, err := time.ParseDuration(flags.restart.Backoff); err != nil {
+					return err
+				}
+				config.Restart.Backoff = flags.restart.Backoff
+			}
+			if cmdFlags.Changed("max_backoff") {
+				if _, err := time.ParseDuration(flags.restart.MaxBackoff); err != nil {
+					return err
+				}
+				config.Restart.MaxBackoff = flags.restart.MaxBackoff
+			}
+			if cmdFlags.Changed("max_restarts") {
+				config.Restart.MaxRestarts = flags.restart.MaxRestarts
+			}
+			if cmdFlags.Changed("window") {
+				if _, err := time.ParseDuration(flags.restart.Window); err != nil {
+					return err
+				}
+				config.Restart.Window = flags.restart.Window
+			}
+			// 检查启动方式是否完整
+			if _, _, err := config.Command(); err != nil {
+				return err
 			}
-			return nil
+			configs.Configs.Servers[config.Name] = config
+			return configs.Configs.Save()
 		},
 	}
 	cmd.Flags().StringVarP(&flags.name, "name", "n", "", locale.GetLocaleMessage("config.flags.name"))
@@ -139,6 +207,26 @@ func newConfigCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&flags.java, "java", "j", "", locale.GetLocaleMessage("create.flags.java"))
 	cmd.Flags().StringSliceVar(&flags.jvmArgs, "jvm_args", []string{}, locale.GetLocaleMessage("create.flags.jvm_args"))
 	cmd.Flags().StringSliceVar(&flags.serverArgs, "server_args", []string{}, locale.GetLocaleMessage("create.flags.server_args"))
+	cmd.Flags().StringVar(&flags.launch.Mode, "launch_mode", "", locale.GetLocaleMessage("config.flags.launch_mode"))
+	cmd.Flags().StringVar(&flags.launch.Jar, "jar", "", locale.GetLocaleMessage("config.flags.jar"))
+	cmd.Flags().StringVar(&flags.launch.MainClass, "main_class", "", locale.GetLocaleMessage("config.flags.main_class"))
+	cmd.Flags().StringSliceVar(&flags.launch.Classpath, "classpath", []string{}, locale.GetLocaleMessage("config.flags.classpath"))
+	cmd.Flags().StringSliceVar(&flags.launch.ArgFiles, "arg_files", []string{}, locale.GetLocaleMessage("config.flags.arg_files"))
+	cmd.Flags().StringVar(&flags.launch.Executable, "executable", "", locale.GetLocaleMessage("config.flags.executable"))
+	cmd.Flags().StringVar(&flags.shutdown.Command, "stop_command", "", locale.GetLocaleMessage("config.flags.stop_command"))
+	cmd.Flags().StringVar(&flags.shutdown.Timeout, "stop_timeout", "", locale.GetLocaleMessage("config.flags.stop_timeout"))
+	cmd.Flags().StringVar(&flags.shutdown.KillTimeout, "kill_timeout", "", locale.GetLocaleMessage("config.flags.kill_timeout"))
+	cmd.Flags().StringVar(&flags.restart.Policy, "restart", "", locale.GetLocaleMessage("config.flags.restart"))
+	cmd.Flags().StringVar(&flags.restart.Backoff, "backoff", "", locale.GetLocaleMessage("config.flags.backoff"))
+	cmd.Flags().StringVar(&flags.restart.MaxBackoff, "max_backoff", "", locale.GetLocaleMessage("config.flags.max_backoff"))
+	cmd.Flags().IntVar(&flags.restart.MaxRestarts, "max_restarts", 0, locale.GetLocaleMessage("config.flags.max_restarts"))
+	cmd.Flags().StringVar(&flags.restart.Window, "window", "", locale.GetLocaleMessage("config.flags.window"))
+	_ = cmd.RegisterFlagCompletionFunc("restart", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return configs.RestartPolicies, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("launch_mode", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return configs.LaunchModes, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.Flags().BoolVar(&flags.delete, "delete", false, locale.GetLocaleMessage("config.flags.delete"))
 	_ = cmd.MarkFlagRequired("name")
 	return cmd
@@ -0,0 +1,163 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// parseCoreID 解析命令行中的核心id
+func parseCoreID(arg string) (configs.Core, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return configs.Core{}, err
+	}
+	core, exists := configs.Configs.Cores[id]
+	if !exists {
+		return configs.Core{}, MCSTErrors.ErrCoreNotFound
+	}
+	return core, nil
+}
+
+// completeCoreIDs 补全核心id, 说明中显示核心的名称
+func completeCoreIDs(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids := make([]int, 0, len(configs.Configs.Cores))
+	for id := range configs.Configs.Cores {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	completions := make([]string, 0, len(ids))
+	for _, id := range ids {
+		core := configs.Configs.Cores[id]
+		description := core.Label
+		if description == "" {
+			description = core.FileName
+		}
+		completions = append(completions, fmt.Sprintf("%d\t%s", id, description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// coreInfo 'download info' 的结构化输出
+type coreInfo struct {
+	configs.Core `yaml:",inline"`
+	Servers      []string `json:"servers" yaml:"servers"`
+}
+
+// serversUsingCore 返回使用此核心创建的服务器; 没有记录核心的旧服务器通过 server.jar 的 SHA-256 判断
+func serversUsingCore(core configs.Core) []string {
+	names := []string{}
+	for name, server := range configs.Configs.Servers {
+		switch {
+		case server.Core != nil:
+			if server.Core.ID == core.ID {
+				names = append(names, name)
+			}
+		case core.SHA256 != "":
+			checksums, err := download.FileChecksums(filepath.Join(configs.ServersDir, name, configs.ServerJar))
+			if err == nil && checksums.SHA256 == core.SHA256 {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newRemoveCoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "remove <id>",
+		Aliases:           []string{"rm"},
+		Short:             locale.GetLocaleMessage("download.remove.short"),
+		Long:              locale.GetLocaleMessage("download.remove.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeCoreIDs,
+		RunE: func(_ *cobra.Command, args []string) error {
+			core, err := parseCoreID(args[0])
+			if err != nil {
+				return err
+			}
+			if servers := serversUsingCore(core); len(servers) != 0 {
+				log.WithField("servers", servers).Warn("以下服务器使用了此核心, 它们的 server.jar 不会受到影响")
+			}
+			delete(configs.Configs.Cores, core.ID)
+			log.Info("已删除核心, 可以使用 'MCST download gc' 清理不再使用的文件")
+			return configs.Configs.Save()
+		},
+	}
+}
+
+func newRenameCoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "rename <id> <label>",
+		Aliases:           []string{"label"},
+		Short:             locale.GetLocaleMessage("download.rename.short"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeCoreIDs,
+		RunE: func(_ *cobra.Command, args []string) error {
+			core, err := parseCoreID(args[0])
+			if err != nil {
+				return err
+			}
+			core.Label = args[1]
+			configs.Configs.Cores[core.ID] = core
+			return configs.Configs.Save()
+		},
+	}
+}
+
+func newCoreInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "info <id>",
+		Short:             locale.GetLocaleMessage("download.info.short"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeCoreIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			core, err := parseCoreID(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err = os.Stat(core.FilePath); err != nil {
+				log.WithError(err).Warn("核心文件不存在")
+			}
+			info := coreInfo{Core: core, Servers: serversUsingCore(core)}
+			return printOutput(cmd.OutOrStdout(), info, keyValueTable(core, map[string]any{"servers": info.Servers}))
+		},
+	}
+}
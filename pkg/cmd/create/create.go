@@ -20,15 +20,17 @@ package create
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/Arama0517/MCST/internal/bytes"
 	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
 	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/installer"
 	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/store"
 	"github.com/apex/log"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/spf13/cobra"
@@ -56,7 +58,7 @@ func New() *cobra.Command {
 		SilenceErrors:     true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			if !flags.eula {
 				return MCSTErrors.ErrEulaRequired
 			}
@@ -96,9 +98,12 @@ func New() *cobra.Command {
 			config.Java.Path = flags.java
 			config.Java.Args = flags.jvmArgs
 			config.ServerArgs = flags.serverArgs
-			if flags.core < 0 || flags.core > len(configs.Configs.Cores) {
+			config.Launch = configs.DefaultLaunch
+			core, exists := configs.Configs.Cores[flags.core]
+			if !exists {
 				return MCSTErrors.ErrCoreNotFound
 			}
+			config.Core = configs.NewServerCore(core)
 			configs.Configs.Servers[config.Name] = config
 
 			// EULA 部分
@@ -109,36 +114,34 @@ eula=true`, time.Now().Format("Mon Jan 02 15:04:05 MST 2006"))
 			if err := os.MkdirAll(filepath.Join(configs.ServersDir, config.Name), 0o755); err != nil {
 				return err
 			}
-			EULAFile, err := os.Open(filepath.Join(configs.ServersDir, config.Name, "eula.txt"))
-			if err != nil {
-				return err
-			}
-			if _, err := EULAFile.WriteString(EULAFileData); err != nil {
-				return err
-			}
-			if err := EULAFile.Close(); err != nil {
+			if err := os.WriteFile(filepath.Join(configs.ServersDir, config.Name, "eula.txt"), []byte(EULAFileData), 0o644); err != nil {
 				return err
 			}
 
-			// 保存
-			srcFile, err := os.Open(configs.Configs.Cores[flags.core].FilePath)
-			if err != nil {
-				return err
-			}
-			dstFile, err := os.Create(filepath.Join(configs.ServersDir, config.Name, "server.jar"))
-			if err != nil {
-				return err
-			}
-			if _, err = io.Copy(dstFile, srcFile); err != nil {
-				return err
-			}
-			if err := os.Chmod(filepath.Join(configs.ServersDir, config.Name, "server.jar"), 0o755); err != nil {
-				return err
-			}
-			if err := srcFile.Close(); err != nil {
-				return err
+			// 校验核心
+			if core.SHA1 == "" && core.SHA256 == "" {
+				log.Warn("核心没有记录校验值, 已跳过校验")
+			} else {
+				checksums, err := download.FileChecksums(core.FilePath)
+				if err != nil {
+					return err
+				}
+				if err = checksums.Verify(core.SHA1, core.SHA256); err != nil {
+					return err
+				}
 			}
-			if err := dstFile.Close(); err != nil {
+
+			// 保存; 优先链接仓库中的文件, 避免重复占用空间; 安装器核心在服务器目录中运行后使用生成的jar
+			serverDir := filepath.Join(configs.ServersDir, config.Name)
+			if core.Installer != nil {
+				config.Launch, err = installer.Run(cmd.Context(), config.Java.Path, serverDir, core)
+				if err != nil {
+					// 服务器还没有保存, 删除安装了一半的目录
+					_ = os.RemoveAll(serverDir)
+					return err
+				}
+				configs.Configs.Servers[config.Name] = config
+			} else if err := store.Link(core.FilePath, filepath.Join(serverDir, configs.ServerJar)); err != nil {
 				return err
 			}
 			if err := configs.Configs.Save(); err != nil {
@@ -0,0 +1,66 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/supervisor"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+func newDaemonCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "daemon",
+		Short:             locale.GetLocaleMessage("daemon.short"),
+		Long:              locale.GetLocaleMessage("daemon.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			listener, err := supervisor.Listen()
+			if err != nil {
+				return err
+			}
+			log.WithField("socket", supervisor.SocketPath()).Info("守护进程已启动")
+			if err = supervisor.New().Serve(cmd.Context(), listener); err != nil {
+				return err
+			}
+			log.Info("守护进程已停止")
+			return nil
+		},
+	}
+}
+
+func newAttachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "attach <name>",
+		Short:             locale.GetLocaleMessage("attach.short"),
+		Long:              locale.GetLocaleMessage("attach.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeServerNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Infof("已连接控制台, 单独输入一行 %s 断开", supervisor.DetachKeys)
+			return supervisor.Attach(cmd.Context(), args[0], cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+}
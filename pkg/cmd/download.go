@@ -19,20 +19,24 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
+	"context"
+	"io"
 	"path/filepath"
 	"sort"
-	"time"
+	"strings"
 
 	api "github.com/Arama0517/MCST/internal/API"
 	"github.com/Arama0517/MCST/internal/configs"
 	"github.com/Arama0517/MCST/internal/download"
 	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/store"
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 )
 
+// downloadBackend 由 'download --backend' 指定, 为空时使用设置中的下载后端
+var downloadBackend string
+
 func newDownloadCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "download",
@@ -43,10 +47,63 @@ func newDownloadCmd() *cobra.Command {
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
 	}
-	cmd.AddCommand(newListCoreCmd(), newLocalCmd(), newRemoteCmd(), newFastMirrorCmd(), newPolarsCmd())
+	cmd.AddCommand(newListCoreCmd(), newLocalCmd(), newRemoteCmd(), newGCCmd(), newVerifyCmd(),
+		newRemoveCoreCmd(), newRenameCoreCmd(), newCoreInfoCmd())
+	for _, provider := range api.Providers() {
+		cmd.AddCommand(newProviderCmd(provider))
+	}
+	cmd.PersistentFlags().StringVar(&downloadBackend, "backend", "", locale.GetLocaleMessage("download.flags.backend"))
+	_ = cmd.RegisterFlagCompletionFunc("backend", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return download.Backends(), cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
+// downloadCore 下载核心并放入仓库; 如果仓库中已经有校验值相同的核心则跳过下载
+func downloadCore(ctx context.Context, downloader *download.Downloader, core configs.Core) error {
+	if cached, ok := findStoredCore(downloader.SHA1, downloader.SHA256); ok {
+		log.WithField("id", cached.ID).Info("仓库中已有相同的核心, 已跳过下载")
+		core.FileName, core.FilePath = cached.FileName, cached.FilePath
+		core.SHA1, core.SHA256 = cached.SHA1, cached.SHA256
+		return addCore(core)
+	}
+	downloader.Backend = downloadBackend
+	path, err := downloader.Download(ctx)
+	if err != nil {
+		return err
+	}
+	blob, err := store.Put(path, downloader.Checksums.SHA256)
+	if err != nil {
+		return err
+	}
+	core.FileName = downloader.FileName
+	core.FilePath = blob
+	core.SHA1 = downloader.Checksums.SHA1
+	core.SHA256 = downloader.Checksums.SHA256
+	return addCore(core)
+}
+
+// findStoredCore 查找校验值相同并且文件仍在仓库中的核心
+func findStoredCore(sha1, sha256 string) (configs.Core, bool) {
+	if sha1 == "" && sha256 == "" {
+		return configs.Core{}, false
+	}
+	for _, core := range configs.Configs.Cores {
+		if (sha256 == "" || strings.EqualFold(core.SHA256, sha256)) &&
+			(sha1 == "" || strings.EqualFold(core.SHA1, sha1)) &&
+			store.Has(core.SHA256) {
+			return core, true
+		}
+	}
+	return configs.Core{}, false
+}
+
+func addCore(core configs.Core) error {
+	core = configs.Configs.AddCore(core)
+	log.WithField("id", core.ID).Info("已添加核心")
+	return configs.Configs.Save()
+}
+
 func newListCoreCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:               "list",
@@ -55,21 +112,23 @@ func newListCoreCmd() *cobra.Command {
 		SilenceErrors:     true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			var keys []int
-			for _, v := range configs.Configs.Cores {
-				keys = append(keys, v.ID)
-			}
-			sort.Ints(keys)
-			for _, key := range keys {
-				data := configs.Configs.Cores[key]
-				log.WithFields(log.Fields{
-					locale.GetLocaleMessage("download.list.output.url"):      data.URL,
-					locale.GetLocaleMessage("download.list.output.filename"): data.FileName,
-					locale.GetLocaleMessage("download.list.output.filepath"): data.FilePath,
-				}).Info(fmt.Sprint(key))
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cores := make([]configs.Core, 0, len(configs.Configs.Cores))
+			for _, core := range configs.Configs.Cores {
+				cores = append(cores, core)
 			}
-			return nil
+			sort.Slice(cores, func(i, j int) bool { return cores[i].ID < cores[j].ID })
+			return printOutput(cmd.OutOrStdout(), cores, func(w io.Writer) error {
+				if err := printRow(w, "ID", "LABEL", "PROVIDER", "FILE_NAME", "URL"); err != nil {
+					return err
+				}
+				for _, core := range cores {
+					if err := printRow(w, core.ID, core.Label, core.Provider, core.FileName, core.URL); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 		},
 	}
 }
@@ -85,16 +144,18 @@ func newLocalCmd() *cobra.Command {
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(*cobra.Command, []string) error {
-			if _, err := os.Stat(path); err != nil {
+			blob, checksums, err := store.Import(path)
+			if err != nil {
 				return err
 			}
-			configs.Configs.Cores[len(configs.Configs.Cores)] = configs.Core{
-				ID:       len(configs.Configs.Cores),
+			return addCore(configs.Core{
+				Provider: "local",
 				URL:      "unknown",
 				FileName: filepath.Base(path),
-				FilePath: path,
-			}
-			return configs.Configs.Save()
+				FilePath: blob,
+				SHA1:     checksums.SHA1,
+				SHA256:   checksums.SHA256,
+			})
 		},
 	}
 	cmd.Flags().StringVarP(&path, "path", "p", "", locale.GetLocaleMessage("download.local.flags.path"))
@@ -112,227 +173,11 @@ func newRemoteCmd() *cobra.Command {
 		SilenceErrors:     true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			downloader := download.NewDownloader(URL)
-			path, err := downloader.Download()
-			if err != nil {
-				return err
-			}
-			configs.Configs.Cores[len(configs.Configs.Cores)] = configs.Core{
-				ID:       len(configs.Configs.Cores),
-				URL:      URL,
-				FileName: downloader.FileName,
-				FilePath: path,
-			}
-			return configs.Configs.Save()
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return downloadCore(cmd.Context(), download.NewDownloader(URL), configs.Core{Provider: "remote", URL: URL})
 		},
 	}
 	cmd.Flags().StringVarP(&URL, "url", "u", "", locale.GetLocaleMessage("download.remote.flags.url"))
 	_ = cmd.MarkFlagRequired("url")
 	return cmd
 }
-
-type fastMirrorCmdFlags struct {
-	core             string
-	minecraftVersion string
-	buildVersion     string
-}
-
-func newFastMirrorCmd() *cobra.Command {
-	flags := fastMirrorCmdFlags{}
-	cmd := &cobra.Command{
-		Use:               "fastmirror",
-		Aliases:           []string{"fm"},
-		Short:             locale.GetLocaleMessage("download.fastmirror.short"),
-		Long:              locale.GetLocaleMessage("download.fastmirror.long"),
-		SilenceUsage:      true,
-		SilenceErrors:     true,
-		Args:              cobra.NoArgs,
-		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			downloader := api.GetFastMirrorDownloader(flags.core, flags.minecraftVersion, flags.buildVersion)
-			path, err := downloader.Download()
-			if err != nil {
-				return err
-			}
-			configs.Configs.Cores[len(configs.Configs.Cores)] = configs.Core{
-				ID:       len(configs.Configs.Cores),
-				URL:      downloader.URL,
-				FileName: downloader.FileName,
-				FilePath: path,
-				ExtrasData: map[string]any{
-					"core":          flags.core,
-					"mc_version":    flags.minecraftVersion,
-					"build_version": flags.buildVersion,
-				},
-			}
-			return configs.Configs.Save()
-		},
-	}
-	cmd.AddCommand(newListFastMirrorCmd())
-	cmd.Flags().StringVarP(&flags.core, "core", "c", "", locale.GetLocaleMessage("download.fastmirror.flags.core"))
-	cmd.Flags().StringVarP(&flags.minecraftVersion, "mc_version", "m", "", locale.GetLocaleMessage("download.fastmirror.flags.mc_version"))
-	cmd.Flags().StringVarP(&flags.buildVersion, "build_version", "b", "", locale.GetLocaleMessage("download.fastmirror.flags.build_version"))
-	_ = cmd.MarkFlagRequired("core")
-	_ = cmd.MarkFlagRequired("mc_version")
-	_ = cmd.MarkFlagRequired("build_version")
-	return cmd
-}
-
-func newListFastMirrorCmd() *cobra.Command {
-	flags := fastMirrorCmdFlags{}
-	cmd := &cobra.Command{
-		Use:               "list",
-		Short:             locale.GetLocaleMessage("download.fastmirror.list.short"),
-		Long:              locale.GetLocaleMessage("download.fastmirror.list.long"),
-		SilenceUsage:      true,
-		SilenceErrors:     true,
-		Args:              cobra.NoArgs,
-		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			fastMirror, err := api.GetFastMirrorData()
-			if err != nil {
-				return err
-			}
-			switch cmdFlags := cmd.Flags(); {
-			case !cmdFlags.Changed("core") && !cmdFlags.Changed("mc_version"):
-				keys := make([]string, 0, len(fastMirror))
-				for k := range fastMirror {
-					keys = append(keys, k)
-				}
-				sort.Strings(keys)
-				for _, key := range keys {
-					data := fastMirror[key]
-					log.WithFields(log.Fields{
-						locale.GetLocaleMessage("download.fastmirror.list.output.recommend"): data.Recommend,
-						locale.GetLocaleMessage("download.fastmirror.list.output.tag"):       data.Tag,
-						locale.GetLocaleMessage("download.fastmirror.list.output.homepage"):  data.Homepage,
-					}).Info(data.Name)
-				}
-			case cmdFlags.Changed("core") && !cmdFlags.Changed("mc_version"):
-				data := fastMirror[flags.core].MinecraftVersions
-				sort.Strings(data)
-				for _, version := range data {
-					log.Info(version)
-				}
-			case cmdFlags.Changed("core") && cmdFlags.Changed("mc_version"):
-				fastMirror, err := api.GetFastMirrorBuildsData(flags.core, flags.minecraftVersion)
-				if err != nil {
-					return err
-				}
-				keys := make([]string, 0, len(fastMirror))
-				for k := range fastMirror {
-					keys = append(keys, k)
-				}
-				sort.Strings(keys)
-				for _, key := range keys {
-					data := fastMirror[key]
-					updateTime, err := time.Parse("2006-01-02T15:04:05", data.UpdateTime)
-					if err != nil {
-						return err
-					}
-					log.WithFields(log.Fields{
-						locale.GetLocaleMessage("download.fastmirror.list.output.sha1"):        data.Sha1,
-						locale.GetLocaleMessage("download.fastmirror.list.output.update_time"): updateTime.Format("2006-01-02 15:04:05"),
-					}).Info(data.CoreVersion)
-				}
-			}
-			return nil
-		},
-	}
-	cmd.Flags().StringVarP(&flags.core, "core", "c", "", locale.GetLocaleMessage("download.fastmirror.flags.core"))
-	cmd.Flags().StringVarP(&flags.minecraftVersion, "mc_version", "m", "", locale.GetLocaleMessage("download.fastmirror.flags.mc_version"))
-	return cmd
-}
-
-func newPolarsCmd() *cobra.Command {
-	var typeID, coreID int
-	cmd := &cobra.Command{
-		Use:               "polars",
-		Short:             locale.GetLocaleMessage("download.polars.short"),
-		Long:              locale.GetLocaleMessage("download.polars.long"),
-		SilenceUsage:      true,
-		SilenceErrors:     true,
-		Args:              cobra.NoArgs,
-		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			polars, err := api.GetPolarsCoresData(typeID)
-			if err != nil {
-				return err
-			}
-			downloader := download.NewDownloader(polars[coreID].DownloadURL)
-			path, err := downloader.Download()
-			if err != nil {
-				return err
-			}
-			configs.Configs.Cores[len(configs.Configs.Cores)] = configs.Core{
-				ID:       len(configs.Configs.Cores),
-				URL:      polars[coreID].DownloadURL,
-				FileName: downloader.FileName,
-				FilePath: path,
-				ExtrasData: map[string]int{
-					"type_id": typeID,
-					"core_id": coreID,
-				},
-			}
-			return configs.Configs.Save()
-		},
-	}
-	cmd.AddCommand(newListPolarsCmd())
-	cmd.Flags().IntVar(&typeID, "type_id", 0, locale.GetLocaleMessage("download.polars.flags.type_id"))
-	cmd.Flags().IntVar(&coreID, "core_id", 0, locale.GetLocaleMessage("download.polars.flags.core_id"))
-	return cmd
-}
-
-func newListPolarsCmd() *cobra.Command {
-	var id int
-	cmd := &cobra.Command{
-		Use:               "list",
-		Short:             locale.GetLocaleMessage("download.polars.list.short"),
-		Long:              locale.GetLocaleMessage("download.polars.list.long"),
-		SilenceUsage:      true,
-		SilenceErrors:     true,
-		Args:              cobra.NoArgs,
-		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			if cmd.Flags().Changed("id") {
-				polars, err := api.GetPolarsCoresData(id)
-				if err != nil {
-					return err
-				}
-				keys := make([]int, 0, len(polars))
-				for k := range polars {
-					keys = append(keys, k)
-				}
-				sort.Ints(keys)
-				for _, k := range keys {
-					data := polars[k]
-					log.WithFields(log.Fields{
-						locale.GetLocaleMessage("download.polars.output.file_name"):    data.Name,
-						locale.GetLocaleMessage("download.polars.output.download_url"): data.DownloadURL,
-					}).Info(fmt.Sprint(data.ID))
-				}
-			} else {
-				polars, err := api.GetPolarsData()
-				if err != nil {
-					return err
-				}
-				keys := make([]string, 0, len(polars))
-				for k := range polars {
-					keys = append(keys, k)
-				}
-				sort.Strings(keys)
-				for _, k := range keys {
-					data := polars[k]
-					log.WithFields(log.Fields{
-						locale.GetLocaleMessage("download.polars.output.name"):        data.Name,
-						locale.GetLocaleMessage("download.polars.output.description"): data.Description,
-					}).Info(fmt.Sprint(data.ID))
-				}
-			}
-			return nil
-		},
-	}
-	cmd.Flags().IntVar(&id, "id", 0, locale.GetLocaleMessage("download.polars.flags.type_id"))
-	return cmd
-}
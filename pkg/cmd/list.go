@@ -20,11 +20,12 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
 
+	"github.com/Arama0517/MCST/internal/bytes"
 	"github.com/Arama0517/MCST/internal/configs"
 	"github.com/Arama0517/MCST/internal/locale"
-	"github.com/apex/log"
 	"github.com/spf13/cobra"
 )
 
@@ -36,21 +37,35 @@ func newListCmd() *cobra.Command {
 		SilenceErrors:     true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			if _, err := fmt.Fprintln(os.Stderr, "已创建的服务器:"); err != nil {
-				return err
-			}
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			servers := make([]configs.Server, 0, len(configs.Configs.Servers))
 			for _, config := range configs.Configs.Servers {
-				log.WithFields(log.Fields{
-					locale.GetLocaleMessage("create.flags.xms"):         config.Java.MinMemory,
-					locale.GetLocaleMessage("create.flags.xmx"):         config.Java.MaxMemory,
-					locale.GetLocaleMessage("create.flags.encoding"):    config.Java.Encoding,
-					locale.GetLocaleMessage("create.flags.java"):        config.Java.Path,
-					locale.GetLocaleMessage("create.flags.jvm_args"):    config.Java.Args,
-					locale.GetLocaleMessage("create.flags.server_args"): config.ServerArgs,
-				}).Info(config.Name)
+				servers = append(servers, config)
 			}
-			return nil
+			sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+			return printOutput(cmd.OutOrStdout(), servers, func(w io.Writer) error {
+				if err := printRow(w, "NAME", "XMS", "XMX", "ENCODING", "JAVA", "LAUNCH", "CORE"); err != nil {
+					return err
+				}
+				for _, config := range servers {
+					core := ""
+					if config.Core != nil {
+						core = fmt.Sprint(config.Core.ID)
+					}
+					if err := printRow(w,
+						config.Name,
+						fmt.Sprintf("%dM", config.Java.MinMemory/bytes.MiB),
+						fmt.Sprintf("%dM", config.Java.MaxMemory/bytes.MiB),
+						config.Java.Encoding,
+						config.Java.Path,
+						config.Launch.Mode,
+						core,
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 		},
 	}
 }
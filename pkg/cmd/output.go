@@ -0,0 +1,104 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式, 由全局参数 --output 选择; 结构化的结果写入标准输出, 日志写入标准错误
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML}
+
+// outputFormat 全局参数 --output 的值
+var outputFormat = outputTable
+
+// printOutput 按 --output 把 data 写入 w; table 格式时调用 table 输出表格, 列之间用制表符分隔
+func printOutput(w io.Writer, data any, table func(w io.Writer) error) error {
+	switch outputFormat {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case outputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		if err := table(tw); err != nil {
+			return err
+		}
+		return tw.Flush()
+	}
+}
+
+// printRow 输出表格的一行, 空值显示为 -
+func printRow(w io.Writer, columns ...any) error {
+	cells := make([]string, 0, len(columns))
+	for _, column := range columns {
+		cell := fmt.Sprint(column)
+		if cell == "" {
+			cell = "-"
+		}
+		cells = append(cells, cell)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(cells, "\t"))
+	return err
+}
+
+// keyValueTable 以 KEY VALUE 两列输出 [structToMap] 的结果, 按键排序
+func keyValueTable(obj any, extra map[string]any) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		result := make(map[string]any)
+		structToMap(obj, "", result)
+		for k, v := range extra {
+			result[k] = v
+		}
+		keys := make([]string, 0, len(result))
+		for k := range result {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if err := printRow(w, "KEY", "VALUE"); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := printRow(w, k, result[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
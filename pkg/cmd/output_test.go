@@ -0,0 +1,88 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/pkg/cmd"
+)
+
+// captureStdout 执行 fn 并返回期间写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+func TestOutputJSON(t *testing.T) {
+	servers := configs.Configs.Servers
+	defer func() { configs.Configs.Servers = servers }()
+	configs.Configs.Servers = map[string]configs.Server{
+		"b": {Name: "b", Java: configs.Java{MinMemory: 1024, MaxMemory: 2048}},
+		"a": {Name: "a", Java: configs.Java{MinMemory: 512, MaxMemory: 1024}},
+	}
+	cmd.ExitFunc = func(code int) {
+		t.Fatalf("退出代码: %d", code)
+	}
+	data := captureStdout(t, func() {
+		cmd.Execute([]string{"list", "--output", "json"})
+	})
+	var result []configs.Server
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("无法解析输出: %v\n%s", err, data)
+	}
+	if len(result) != 2 || result[0].Name != "a" || result[1].Java.MaxMemory != 2048 {
+		t.Fatalf("输出不正确: %+v", result)
+	}
+}
+
+func TestUnknownOutputFormat(t *testing.T) {
+	var code int
+	cmd.ExitFunc = func(c int) {
+		code = c
+	}
+	data := captureStdout(t, func() {
+		cmd.Execute([]string{"list", "--output", "xml"})
+	})
+	if code != MCSTErrors.RunFail {
+		t.Fatalf("预期退出代码 %d, 实际 %d", MCSTErrors.RunFail, code)
+	}
+	if len(data) != 0 {
+		t.Fatalf("预期没有输出, 实际为 %q", data)
+	}
+}
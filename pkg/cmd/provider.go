@@ -0,0 +1,296 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	api "github.com/Arama0517/MCST/internal/API"
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+type providerCmdFlags struct {
+	core             string
+	minecraftVersion string
+	buildVersion     string
+}
+
+type listProviderCmdFlags struct {
+	providerCmdFlags
+	limit  int
+	offset int
+	all    bool
+}
+
+// addFlags 添加所有来源共用的参数
+func (f *providerCmdFlags) addFlags(cmd *cobra.Command, provider api.Provider, build bool) {
+	cmd.Flags().StringVarP(&f.core, "core", "c", "", locale.GetLocaleMessage("download.provider.flags.core"))
+	cmd.Flags().StringVarP(&f.minecraftVersion, "mc_version", "m", "", locale.GetLocaleMessage("download.provider.flags.mc_version"))
+	cmd.Flags().BoolVar(&api.Refresh, "refresh", false, locale.GetLocaleMessage("download.provider.flags.refresh"))
+	_ = cmd.RegisterFlagCompletionFunc("core", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		projects, err := provider.Projects(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		result := make([]string, 0, len(projects))
+		for _, project := range projects {
+			result = append(result, project.ID+"\t"+project.Name)
+		}
+		return result, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("mc_version", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		versions, err := provider.Versions(cmd.Context(), f.core)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		result := make([]string, 0, len(versions))
+		for _, version := range versions {
+			result = append(result, version.ID)
+		}
+		return result, cobra.ShellCompDirectiveNoFileComp
+	})
+	if !build {
+		return
+	}
+	cmd.Flags().StringVarP(&f.buildVersion, "build_version", "b", "", locale.GetLocaleMessage("download.provider.flags.build_version"))
+	_ = cmd.RegisterFlagCompletionFunc("build_version", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		builds, err := provider.Builds(cmd.Context(), f.core, f.minecraftVersion)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		result := make([]string, 0, len(builds))
+		for _, build := range builds {
+			result = append(result, build.ID+"\t"+build.Description)
+		}
+		return result, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// defaultCore 来源只有一个核心时可以省略 --core
+func (f *providerCmdFlags) defaultCore(ctx context.Context, provider api.Provider) error {
+	if f.core != "" {
+		return nil
+	}
+	projects, err := provider.Projects(ctx)
+	if err != nil {
+		return err
+	}
+	if len(projects) != 1 {
+		return MCSTErrors.ErrCoreRequired
+	}
+	f.core = projects[0].ID
+	return nil
+}
+
+// resolve 把 latest, latest-stable, 1.20.x 这样的Minecraft版本和构建版本解析为具体的版本
+func (f *providerCmdFlags) resolve(ctx context.Context, provider api.Provider) error {
+	if !api.IsSpec(f.minecraftVersion) && !api.IsSpec(f.buildVersion) {
+		return nil
+	}
+	minecraftVersion, err := api.ResolveVersion(ctx, provider, f.core, f.minecraftVersion)
+	if err != nil {
+		return err
+	}
+	buildVersion, err := api.ResolveBuild(ctx, provider, f.core, minecraftVersion, f.buildVersion)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"mc_version":    fmt.Sprintf("%s -> %s", f.minecraftVersion, minecraftVersion),
+		"build_version": fmt.Sprintf("%s -> %s", f.buildVersion, buildVersion),
+	}).Info("已解析版本")
+	f.minecraftVersion, f.buildVersion = minecraftVersion, buildVersion
+	return nil
+}
+
+// newProviderCmd 为一个核心来源生成 'MCST download <provider>' 命令
+func newProviderCmd(provider api.Provider) *cobra.Command {
+	flags := providerCmdFlags{}
+	cmd := &cobra.Command{
+		Use:               provider.Name(),
+		Short:             locale.GetLocaleMessage("download." + provider.Name() + ".short"),
+		Long:              locale.GetLocaleMessage("download." + provider.Name() + ".long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if err := flags.defaultCore(ctx, provider); err != nil {
+				return err
+			}
+			if err := flags.resolve(ctx, provider); err != nil {
+				return err
+			}
+			artifact, err := provider.Resolve(ctx, flags.core, flags.minecraftVersion, flags.buildVersion)
+			if err != nil {
+				return err
+			}
+			downloader := download.NewDownloader(artifact.URL)
+			downloader.SHA1 = artifact.SHA1
+			downloader.SHA256 = artifact.SHA256
+			return downloadCore(ctx, downloader, configs.Core{
+				Provider:         provider.Name(),
+				Project:          flags.core,
+				MinecraftVersion: flags.minecraftVersion,
+				Build:            flags.buildVersion,
+				URL:              artifact.URL,
+				JavaMajor:        artifact.JavaMajor,
+				Installer:        artifact.Installer,
+				ExtrasData:       artifact.ExtrasData,
+			})
+		},
+	}
+	if aliaser, ok := provider.(api.Aliaser); ok {
+		cmd.Aliases = aliaser.Aliases()
+	}
+	cmd.AddCommand(newListProviderCmd(provider))
+	flags.addFlags(cmd, provider, true)
+	return cmd
+}
+
+func newListProviderCmd(provider api.Provider) *cobra.Command {
+	flags := listProviderCmdFlags{}
+	cmd := &cobra.Command{
+		Use:               "list",
+		Short:             locale.GetLocaleMessage("download.provider.list.short"),
+		Long:              locale.GetLocaleMessage("download.provider.list.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			switch cmdFlags := cmd.Flags(); {
+			case !cmdFlags.Changed("core") && !cmdFlags.Changed("mc_version"):
+				return listProjects(ctx, cmd.OutOrStdout(), provider)
+			case !cmdFlags.Changed("core"):
+				if err := flags.defaultCore(ctx, provider); err != nil {
+					return err
+				}
+				return listBuilds(ctx, cmd.OutOrStdout(), provider, flags)
+			case !cmdFlags.Changed("mc_version"):
+				versions, err := provider.Versions(ctx, flags.core)
+				if err != nil {
+					return err
+				}
+				if versions == nil {
+					return listBuilds(ctx, cmd.OutOrStdout(), provider, flags)
+				}
+				return listVersions(cmd.OutOrStdout(), versions)
+			default:
+				return listBuilds(ctx, cmd.OutOrStdout(), provider, flags)
+			}
+		},
+	}
+	flags.addFlags(cmd, provider, false)
+	cmd.Flags().IntVar(&flags.limit, "limit", 25, locale.GetLocaleMessage("download.provider.list.flags.limit"))
+	cmd.Flags().IntVar(&flags.offset, "offset", 0, locale.GetLocaleMessage("download.provider.list.flags.offset"))
+	cmd.Flags().BoolVar(&flags.all, "all", false, locale.GetLocaleMessage("download.provider.list.flags.all"))
+	cmd.MarkFlagsMutuallyExclusive("all", "limit")
+	cmd.MarkFlagsMutuallyExclusive("all", "offset")
+	return cmd
+}
+
+func listProjects(ctx context.Context, w io.Writer, provider api.Provider) error {
+	projects, err := provider.Projects(ctx)
+	if err != nil {
+		return err
+	}
+	projects = append(make([]api.Project, 0, len(projects)), projects...)
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return printOutput(w, projects, func(w io.Writer) error {
+		if err := printRow(w, "ID", "NAME", "RECOMMEND", "HOMEPAGE", "DESCRIPTION"); err != nil {
+			return err
+		}
+		for _, project := range projects {
+			if err := printRow(w, project.ID, project.Name, project.Recommend, project.Homepage, project.Description); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func listVersions(w io.Writer, versions []api.Version) error {
+	versions = api.SortVersions(versions)
+	return printOutput(w, versions, func(w io.Writer) error {
+		if err := printRow(w, "ID", "STABLE", "TIME"); err != nil {
+			return err
+		}
+		for _, version := range versions {
+			if err := printRow(w, version.ID, version.Stable, formatTime(version.Time)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func listBuilds(ctx context.Context, w io.Writer, provider api.Provider, flags listProviderCmdFlags) error {
+	var builds []api.Build
+	var count int
+	var err error
+	if flags.all {
+		builds, err = provider.Builds(ctx, flags.core, flags.minecraftVersion)
+		builds, count = api.SortBuilds(builds), len(builds)
+	} else {
+		builds, count, err = api.BuildsPage(ctx, provider, flags.core, flags.minecraftVersion, flags.offset, flags.limit)
+	}
+	if err != nil {
+		return err
+	}
+	builds = append(make([]api.Build, 0, len(builds)), builds...)
+	err = printOutput(w, builds, func(w io.Writer) error {
+		if err := printRow(w, "ID", "UPDATE_TIME", "STABLE", "SHA1", "SHA256", "DESCRIPTION"); err != nil {
+			return err
+		}
+		for _, build := range builds {
+			if err := printRow(w, build.ID, formatTime(build.Time), build.Stable, build.SHA1, build.SHA256, build.Description); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !flags.all && flags.offset+len(builds) < count {
+		log.WithFields(log.Fields{"offset": flags.offset, "count": count}).
+			Infof("还有 %d 个构建, 使用 --offset 查看更多或使用 --all 查看全部", count-flags.offset-len(builds))
+	}
+	return nil
+}
+
+// formatTime 格式化表格中的时间, 未知的时间输出为空
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
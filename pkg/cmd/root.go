@@ -19,13 +19,21 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
 	"reflect"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/Arama0517/MCST/internal/build"
 	"github.com/Arama0517/MCST/internal/configs"
 	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/requests"
 	"github.com/Arama0517/MCST/pkg/cmd/create"
 	"github.com/Arama0517/MCST/pkg/cmd/settings"
 	"github.com/apex/log"
@@ -48,9 +56,23 @@ func init() {
 }
 
 func Execute(args []string) {
+	// 第一次 Ctrl+C 取消正在进行的操作, 取消后恢复默认行为, 再按一次会直接退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	cmd := newRootCmd()
 	cmd.SetArgs(args)
-	if err := cmd.Execute(); err != nil && err.Error() != "interrupt" {
+	err := cmd.ExecuteContext(ctx)
+	switch {
+	case err == nil, err.Error() == "interrupt":
+	case errors.Is(err, context.Canceled):
+		log.Warn("操作已取消")
+		ExitFunc(MCSTErrors.Canceled)
+	default:
 		log.WithError(err).Error("出现错误!")
 		ExitFunc(MCSTErrors.RunFail)
 	}
@@ -67,10 +89,14 @@ func newRootCmd() *cobra.Command {
 		SilenceErrors:     true,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
-		PersistentPreRun: func(*cobra.Command, []string) {
+		PersistentPreRunE: func(*cobra.Command, []string) error {
 			if debug {
 				log.SetLevel(log.DebugLevel)
 			}
+			if !slices.Contains(outputFormats, outputFormat) {
+				return fmt.Errorf("%w: %s", MCSTErrors.ErrUnknownOutputFormat, outputFormat)
+			}
+			return nil
 		},
 		PostRun: func(*cobra.Command, []string) {
 			log.Info("运行成功")
@@ -78,12 +104,23 @@ func newRootCmd() *cobra.Command {
 	}
 	cmd.SetVersionTemplate("{{.Version}}")
 	cmd.PersistentFlags().BoolVar(&debug, "debug", false, locale.GetLocaleMessage("root.flags.debug"))
+	cmd.PersistentFlags().BoolVar(&requests.Offline, "offline", false, locale.GetLocaleMessage("root.flags.offline"))
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, locale.GetLocaleMessage("root.flags.output"))
+	_ = cmd.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.AddCommand(
 		create.New(),
 		newDownloadCmd(),
 		newConfigCmd(),
 		newStartCmd(),
+		newDaemonCmd(),
+		newAttachCmd(),
+		newStopCmd(),
+		newRestartCmd(),
 		newListCmd(),
+		newStatusCmd(),
+		newUpgradeCmd(),
 		settings.New(),
 		newManCmd(),
 	)
@@ -99,7 +136,7 @@ func structToMap(obj any, parentKey string, result map[string]any) {
 		value := v.Field(i)
 
 		// 构建完整的键
-		tag := field.Tag.Get("yaml")
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
 		if tag == "" {
 			tag = field.Name
 		}
@@ -108,6 +145,12 @@ func structToMap(obj any, parentKey string, result map[string]any) {
 			key = parentKey + "." + tag
 		}
 
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
 		switch value.Kind() {
 		case reflect.Struct:
 			structToMap(value.Interface(), key, result)
@@ -21,9 +21,14 @@ package settings
 import (
 	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	api "github.com/Arama0517/MCST/internal/API"
+	"github.com/Arama0517/MCST/internal/bytes"
 	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
 	"github.com/Arama0517/MCST/internal/locale"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/language"
@@ -43,15 +48,33 @@ func New() *cobra.Command {
 				Message: "请选择一个要更改的设置",
 				Options: []string{
 					"Language",
+					"Backend",
 					"Aria2",
+					"Segmented",
+					"Cache",
+					"Network",
+					"IDM",
+					"External",
 					"Auto accept EULA",
 				},
 				Description: func(value string, _ int) string {
 					switch value {
 					case "Language":
 						return locale.GetLocaleMessage("settings.language")
+					case "Backend":
+						return locale.GetLocaleMessage("settings.backend")
 					case "Aria2":
 						return locale.GetLocaleMessage("settings.aria2")
+					case "Segmented":
+						return locale.GetLocaleMessage("settings.segmented")
+					case "Cache":
+						return locale.GetLocaleMessage("settings.cache")
+					case "Network":
+						return locale.GetLocaleMessage("settings.network")
+					case "IDM":
+						return locale.GetLocaleMessage("settings.idm")
+					case "External":
+						return locale.GetLocaleMessage("settings.external")
 					case "Auto accept EULA":
 						return locale.GetLocaleMessage("settings.auto_accept_eula")
 					default:
@@ -64,8 +87,20 @@ func New() *cobra.Command {
 			switch result {
 			case "Language":
 				return caseLanguage()
+			case "Backend":
+				return caseBackend()
 			case "Aria2":
 				return caseAria2()
+			case "Segmented":
+				return caseSegmented()
+			case "Cache":
+				return caseCache()
+			case "Network":
+				return caseNetwork()
+			case "IDM":
+				return caseIDM()
+			case "External":
+				return caseExternal()
 			case "Auto accept EULA":
 				return caseAutoAcceptEULA()
 			}
@@ -118,6 +153,199 @@ func caseLanguage() error {
 	return configs.Configs.Save()
 }
 
+func caseBackend() error {
+	var result string
+	if err := survey.AskOne(&survey.Select{
+		Message: "请选择一个下载后端",
+		Options: download.Backends(),
+		Default: configs.Configs.Settings.Backend,
+	}, &result); err != nil {
+		return err
+	}
+	configs.Configs.Settings.Backend = result
+	return configs.Configs.Save()
+}
+
+// cacheDefault 缓存设置中表示默认缓存时间的选项
+const cacheDefault = "default"
+
+func caseCache() error {
+	options := []string{cacheDefault}
+	for _, provider := range api.Providers() {
+		options = append(options, provider.Name())
+	}
+	var provider string
+	if err := survey.AskOne(&survey.Select{
+		Message: "请选择要设置缓存时间的来源",
+		Options: options,
+	}, &provider); err != nil {
+		return err
+	}
+	cache := &configs.Configs.Settings.Cache
+	if provider == cacheDefault {
+		var result string
+		if err := survey.AskOne(&survey.Input{
+			Message: "请输入默认的缓存时间(例如 30m, 6h)",
+			Default: cache.TTL,
+		}, &result, survey.WithValidator(durationValidator)); err != nil {
+			return err
+		}
+		cache.TTL = result
+		return configs.Configs.Save()
+	}
+	var result string
+	if err := survey.AskOne(&survey.Input{
+		Message: "请输入缓存时间(例如 30m, 6h, 留空则使用默认值)",
+		Default: cache.Providers[provider],
+	}, &result, survey.WithValidator(func(ans any) error {
+		if ans == "" {
+			return nil
+		}
+		return durationValidator(ans)
+	})); err != nil {
+		return err
+	}
+	if cache.Providers == nil {
+		cache.Providers = map[string]string{}
+	}
+	if result == "" {
+		delete(cache.Providers, provider)
+	} else {
+		cache.Providers[provider] = result
+	}
+	return configs.Configs.Save()
+}
+
+func caseNetwork() error {
+	var result string
+	if err := survey.AskOne(&survey.Select{
+		Message: "请选择一个网络的配置项",
+		Options: []string{
+			"connect-timeout",
+			"timeout",
+			"retries",
+			"retry-wait",
+			"proxy",
+			"ca-bundle",
+		},
+		Description: func(value string, _ int) string {
+			switch value {
+			case "connect-timeout":
+				return locale.GetLocaleMessage("settings.network.connect_timeout")
+			case "timeout":
+				return locale.GetLocaleMessage("settings.network.timeout")
+			case "retries":
+				return locale.GetLocaleMessage("settings.network.retries")
+			case "retry-wait":
+				return locale.GetLocaleMessage("settings.network.retry_wait")
+			case "proxy":
+				return locale.GetLocaleMessage("settings.network.proxy")
+			case "ca-bundle":
+				return locale.GetLocaleMessage("settings.network.ca_bundle")
+			default:
+				return ""
+			}
+		},
+	}, &result); err != nil {
+		return err
+	}
+	network := &configs.Configs.Settings.Network
+	switch result {
+	case "connect-timeout":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入连接超时时间(例如 10s)", Default: network.ConnectTimeout}, &result,
+			survey.WithValidator(durationValidator)); err != nil {
+			return err
+		}
+		network.ConnectTimeout = result
+	case "timeout":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入请求超时时间(例如 30s)", Default: network.Timeout}, &result,
+			survey.WithValidator(durationValidator)); err != nil {
+			return err
+		}
+		network.Timeout = result
+	case "retries":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入重试次数", Default: strconv.Itoa(network.Retries)}, &result,
+			survey.WithValidator(func(ans any) error {
+				if ans == "0" {
+					return nil
+				}
+				return aria2IntValidator(ans)
+			})); err != nil {
+			return err
+		}
+		network.Retries, _ = strconv.Atoi(result)
+	case "retry-wait":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入第一次重试前等待的时间(例如 1s)", Default: network.RetryWait}, &result,
+			survey.WithValidator(durationValidator)); err != nil {
+			return err
+		}
+		network.RetryWait = result
+	case "proxy":
+		if err := survey.AskOne(&survey.Input{
+			Message: "请输入代理地址(例如 http://127.0.0.1:7890, socks5://127.0.0.1:1080, 留空则使用环境变量)",
+			Default: network.Proxy,
+		}, &result); err != nil {
+			return err
+		}
+		network.Proxy = result
+	case "ca-bundle":
+		if err := survey.AskOne(&survey.Input{
+			Message: "请输入CA证书文件的路径(PEM格式, 留空则只使用系统证书)",
+			Default: network.CABundle,
+		}, &result); err != nil {
+			return err
+		}
+		network.CABundle = result
+	}
+	return configs.Configs.Save()
+}
+
+func durationValidator(ans any) error {
+	result, ok := ans.(string)
+	if !ok {
+		return errors.New("invalid answer type")
+	}
+	_, err := time.ParseDuration(result)
+	return err
+}
+
+func caseIDM() error {
+	var result string
+	if err := survey.AskOne(&survey.Input{
+		Message: "请输入IDM的安装目录",
+		Default: configs.Configs.Settings.IDM.RootDir,
+	}, &result); err != nil {
+		return err
+	}
+	configs.Configs.Settings.IDM.RootDir = result
+	return configs.Configs.Save()
+}
+
+func caseExternal() error {
+	var command, args string
+	if err := survey.AskOne(&survey.Input{
+		Message: "请输入外部下载程序的路径",
+		Default: configs.Configs.Settings.External.Command,
+	}, &command); err != nil {
+		return err
+	}
+	if err := survey.AskOne(&survey.Input{
+		Message: "请输入参数(用空格分隔, 可以使用 {url} {dir} {file} {path})",
+		Default: strings.Join(configs.Configs.Settings.External.Args, " "),
+	}, &args); err != nil {
+		return err
+	}
+	configs.Configs.Settings.External = configs.External{
+		Command: command,
+		Args:    strings.Fields(args),
+	}
+	return configs.Configs.Save()
+}
+
 func aria2IntValidator(ans any) error {
 	result, ok := ans.(string)
 	if !ok {
@@ -138,21 +366,24 @@ func caseAria2() error {
 	if err := survey.AskOne(&survey.Select{
 		Message: "请选择一个Aria2的配置项",
 		Options: []string{
-			"enabled",
 			"retry-wait",
 			"split",
 			"max-connection-per-server",
+			"rpc-url",
+			"rpc-secret",
 		},
 		Description: func(value string, _ int) string {
 			switch value {
-			case "enabled":
-				return locale.GetLocaleMessage("settings.aria2.enabled")
 			case "retry-wait":
 				return locale.GetLocaleMessage("settings.aria2.retry_wait")
 			case "split":
 				return locale.GetLocaleMessage("settings.aria2.split")
 			case "max-connection-per-server":
 				return locale.GetLocaleMessage("settings.aria2.max_connection_per_server")
+			case "rpc-url":
+				return locale.GetLocaleMessage("settings.aria2.rpc_url")
+			case "rpc-secret":
+				return locale.GetLocaleMessage("settings.aria2.rpc_secret")
 			default:
 				return ""
 			}
@@ -161,12 +392,24 @@ func caseAria2() error {
 		return err
 	}
 	switch result {
-	case "enabled":
-		enabled := false
-		if err := survey.AskOne(&survey.Confirm{Message: "是否启用Aria2"}, &enabled); err != nil {
+	case "rpc-url":
+		if err := survey.AskOne(&survey.Input{
+			Message: "请输入aria2的JSON-RPC地址(留空则自动启动aria2c)",
+			Default: configs.Configs.Settings.Aria2.RPCURL,
+		}, &result, survey.WithValidator(func(ans any) error {
+			if rpcURL, _ := ans.(string); rpcURL != "" {
+				return download.CheckAria2RPCURL(rpcURL)
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+		configs.Configs.Settings.Aria2.RPCURL = result
+	case "rpc-secret":
+		if err := survey.AskOne(&survey.Password{Message: "请输入aria2的RPC密钥"}, &result); err != nil {
 			return err
 		}
-		configs.Configs.Settings.Aria2.Enable = enabled
+		configs.Configs.Settings.Aria2.RPCSecret = result
 	case "retry-wait":
 		if err := survey.AskOne(
 			&survey.Input{Message: "请输入参数的值"}, &result,
@@ -195,6 +438,67 @@ func caseAria2() error {
 	return configs.Configs.Save()
 }
 
+func caseSegmented() error {
+	var result string
+	if err := survey.AskOne(&survey.Select{
+		Message: "请选择一个分段下载的配置项",
+		Options: []string{
+			"split",
+			"max-connection-per-server",
+			"min-split-size",
+		},
+		Description: func(value string, _ int) string {
+			switch value {
+			case "split":
+				return locale.GetLocaleMessage("settings.aria2.split")
+			case "max-connection-per-server":
+				return locale.GetLocaleMessage("settings.aria2.max_connection_per_server")
+			case "min-split-size":
+				return locale.GetLocaleMessage("settings.aria2.min_split_size")
+			default:
+				return ""
+			}
+		},
+	}, &result); err != nil {
+		return err
+	}
+	switch result {
+	case "split":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入参数的值"}, &result,
+			survey.WithValidator(aria2IntValidator)); err != nil {
+			return err
+		}
+		split, _ := strconv.Atoi(result)
+		configs.Configs.Settings.Segmented.Split = split
+	case "max-connection-per-server":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入参数的值"}, &result,
+			survey.WithValidator(aria2IntValidator)); err != nil {
+			return err
+		}
+		maxConnectionPerServer, _ := strconv.Atoi(result)
+		configs.Configs.Settings.Segmented.MaxConnectionPerServer = maxConnectionPerServer
+	case "min-split-size":
+		if err := survey.AskOne(
+			&survey.Input{Message: "请输入参数的值"}, &result,
+			survey.WithValidator(sizeValidator)); err != nil {
+			return err
+		}
+		configs.Configs.Settings.Segmented.MinSplitSize = result
+	}
+	return configs.Configs.Save()
+}
+
+func sizeValidator(ans any) error {
+	result, ok := ans.(string)
+	if !ok {
+		return errors.New("invalid answer type")
+	}
+	_, err := bytes.ToBytes(result)
+	return err
+}
+
 func caseAutoAcceptEULA() error {
 	result := false
 	if err := survey.AskOne(&survey.Confirm{Message: "是否启用自动同意EULA?"}, &result); err != nil {
@@ -19,69 +19,105 @@
 package cmd
 
 import (
-	"fmt"
-	"path/filepath"
+	"context"
+	"io"
+	"sort"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
 	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/supervisor"
 	"github.com/apex/log"
-	"github.com/go-cmd/cmd"
 	"github.com/spf13/cobra"
 )
 
 func newStartCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:               "start",
+	var detach bool
+	cmd := &cobra.Command{
+		Use:               "start [name]",
 		Short:             locale.GetLocaleMessage("start.short"),
 		Long:              locale.GetLocaleMessage("start.long"),
 		SilenceUsage:      true,
 		SilenceErrors:     true,
-		Args:              cobra.NoArgs,
-		ValidArgsFunction: cobra.NoFileCompletions,
-		RunE: func(*cobra.Command, []string) error {
-			serverName := ""
-			var serverNames []string
-			for _, server := range configs.Configs.Servers {
-				serverNames = append(serverNames, server.Name)
-			}
-			if err := survey.AskOne(&survey.Select{
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeServerNames,
+		RunE: func(command *cobra.Command, args []string) error {
+			var serverName string
+			if len(args) == 1 {
+				serverName = args[0]
+			} else if err := survey.AskOne(&survey.Select{
 				Message: "请选择一个服务器",
-				Options: serverNames,
+				Options: serverNames(),
 			}, &serverName); err != nil {
 				return err
 			}
-			config := configs.Configs.Servers[serverName]
-
-			javaCmd := cmd.NewCmd(config.Java.Path)
-			javaCmd.Dir = filepath.Join(configs.ServersDir, config.Name)
-			javaCmd.Args = append(javaCmd.Args,
-				fmt.Sprintf("-Xms%d", config.Java.MinMemory),
-				fmt.Sprintf("-Xmx%d", config.Java.MaxMemory),
-				fmt.Sprintf("-Dfile.encoding=%s", config.Java.Encoding))
-			javaCmd.Args = append(javaCmd.Args, config.Java.Args...)
-			javaCmd.Args = append(javaCmd.Args, "-jar", "server.jar")
-			javaCmd.Args = append(javaCmd.Args, config.ServerArgs...)
-			stdout := make(chan string, 1)
-			stderr := make(chan string, 1)
-			javaCmd.Stdout = stdout
-			javaCmd.Stderr = stderr
-			statusChan := javaCmd.Start()
+			config, exists := configs.Configs.Servers[serverName]
+			if !exists {
+				return MCSTErrors.ErrServerNotFound
+			}
 
-			for {
-				select {
-				case finalStatus := <-statusChan:
-					if finalStatus.Exit != 0 {
-						log.WithField("错误代码", finalStatus.Exit).Error("服务器进程未正常退出")
-						ExitFunc(finalStatus.Exit)
-					}
-					return nil
-				case str := <-stdout:
-					log.Info(str)
-				case str := <-stderr:
-					log.Error(str)
+			if detach {
+				if err := supervisor.EnsureDaemon(command.Context()); err != nil {
+					return err
 				}
+				if err := supervisor.Do(supervisor.Request{Action: supervisor.ActionStart, Server: &config}); err != nil {
+					return err
+				}
+				log.WithField("name", config.Name).Infof("服务器已在后台启动, 使用 'MCST attach %s' 连接控制台", config.Name)
+				return nil
+			}
+
+			exit, err := runServer(command.Context(), config, command.InOrStdin(), command.OutOrStdout(), command.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if exit != 0 {
+				log.WithField("错误代码", exit).Error("服务器进程未正常退出")
+				ExitFunc(exit)
 			}
+			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&detach, "detach", "d", false, locale.GetLocaleMessage("start.flags.detach"))
+	return cmd
+}
+
+// serverNames 返回所有服务器的名称, 按名称排序
+func serverNames() []string {
+	names := make([]string, 0, len(configs.Configs.Servers))
+	for name := range configs.Configs.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeServerNames 补全服务器名称
+func completeServerNames(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return serverNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// runServer 在前台运行服务器, 按照自动重启策略重启, 返回最后一次的退出代码; stdin 按行转发到服务器的控制台, 服务器的输出原样写入 stdout 和 stderr
+//
+// ctx 取消后停止服务器并等待它退出
+func runServer(ctx context.Context, config configs.Server, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	runner, err := supervisor.Run(config, stdout, stderr)
+	if err != nil {
+		return 0, err
+	}
+	go supervisor.ForwardLines(runner.Console(), stdin)
+
+	// 收到 Ctrl+C 或 SIGTERM 后像 'MCST stop' 一样停止服务器
+	select {
+	case <-ctx.Done():
+		runner.Shutdown(supervisor.ReasonSignal, 0)
+		exit, _ := runner.Wait()
+		return exit, context.Cause(ctx)
+	case <-runner.Done():
+		return runner.Wait()
+	}
 }
@@ -0,0 +1,64 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/pkg/cmd"
+)
+
+func TestStartForwardsStdin(t *testing.T) {
+	runDir, serversDir, servers := configs.RunDir, configs.ServersDir, configs.Configs.Servers
+	defer func() { configs.RunDir, configs.ServersDir, configs.Configs.Servers = runDir, serversDir, servers }()
+	configs.RunDir, configs.ServersDir = t.TempDir(), t.TempDir()
+	if err := os.Mkdir(filepath.Join(configs.ServersDir, "console"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	configs.Configs.Servers = map[string]configs.Server{"console": {
+		Name:       "console",
+		ServerArgs: []string{"-c", `read line; echo "> $line"`},
+		Launch:     configs.Launch{Mode: configs.LaunchExecutable, Executable: "sh"},
+	}}
+	cmd.ExitFunc = func(code int) {
+		t.Fatalf("退出代码: %d", code)
+	}
+
+	stdin, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = oldStdin }()
+	if _, err = stdinWriter.WriteString("say hello\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	data := captureStdout(t, func() {
+		cmd.Execute([]string{"start", "console"})
+	})
+	if got := strings.TrimSpace(string(data)); got != "> say hello" {
+		t.Fatalf("预期输出 %q, 实际为 %q", "> say hello", got)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Arama0517/MCST/internal/bytes"
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/properties"
+	"github.com/Arama0517/MCST/internal/slp"
+	"github.com/Arama0517/MCST/internal/supervisor"
+	"github.com/apex/log"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/spf13/cobra"
+)
+
+// cpuSampleInterval 计算 CPU 使用率时采样的时间
+const cpuSampleInterval = 500 * time.Millisecond
+
+// pingTimeout Server List Ping 的超时时间
+const pingTimeout = 2 * time.Second
+
+// serverStatus 'MCST status' 输出的服务器状态
+type serverStatus struct {
+	Name          string    `json:"name" yaml:"name"`
+	Running       bool      `json:"running" yaml:"running"`
+	Parked        bool      `json:"parked" yaml:"parked"` // 反复崩溃, 已停止自动重启
+	PID           int       `json:"pid,omitempty" yaml:"pid,omitempty"`
+	StartTime     time.Time `json:"start_time,omitempty" yaml:"start_time,omitempty"`
+	Uptime        string    `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+	CPUPercent    float64   `json:"cpu_percent" yaml:"cpu_percent"` // 包括子进程
+	RSS           uint64    `json:"rss" yaml:"rss"`                 // 包括子进程
+	Port          int       `json:"port" yaml:"port"`
+	Version       string    `json:"version,omitempty" yaml:"version,omitempty"`
+	PlayersOnline int       `json:"players_online" yaml:"players_online"`
+	PlayersMax    int       `json:"players_max" yaml:"players_max"`
+	MOTD          string    `json:"motd,omitempty" yaml:"motd,omitempty"`
+	ExitCode      int       `json:"exit_code" yaml:"exit_code"`               // 上次退出的代码
+	Reason        string    `json:"reason,omitempty" yaml:"reason,omitempty"` // 上次停止的原因
+}
+
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "status [name]",
+		Short:             locale.GetLocaleMessage("status.short"),
+		Long:              locale.GetLocaleMessage("status.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeServerNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := serverNames()
+			if len(args) == 1 {
+				if _, exists := configs.Configs.Servers[args[0]]; !exists {
+					return MCSTErrors.ErrServerNotFound
+				}
+				names = args
+			}
+			// 采样 CPU 和 Server List Ping 都需要等待, 同时获取所有服务器的状态
+			statuses := make([]serverStatus, len(names))
+			var wg sync.WaitGroup
+			for i, name := range names {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					statuses[i] = getServerStatus(configs.Configs.Servers[name])
+				}()
+			}
+			wg.Wait()
+			return printOutput(cmd.OutOrStdout(), statuses, func(w io.Writer) error {
+				if err := printRow(w, "NAME", "STATUS", "PID", "UPTIME", "CPU", "RSS", "PORT", "PLAYERS", "MOTD"); err != nil {
+					return err
+				}
+				for _, status := range statuses {
+					if !status.Running {
+						if err := printRow(w, status.Name, status.state(), "", "", "", "", status.Port, "", ""); err != nil {
+							return err
+						}
+						continue
+					}
+					if err := printRow(w,
+						status.Name,
+						status.state(),
+						status.PID,
+						status.Uptime,
+						fmt.Sprintf("%.1f%%", status.CPUPercent),
+						fmt.Sprintf("%dM", status.RSS/bytes.MiB),
+						status.Port,
+						fmt.Sprintf("%d/%d", status.PlayersOnline, status.PlayersMax),
+						status.MOTD,
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// state 返回表格中 STATUS 列的内容
+func (s serverStatus) state() string {
+	switch {
+	case s.Running:
+		return "running"
+	case s.Parked:
+		return "parked"
+	case s.Reason != "":
+		return fmt.Sprintf("stopped (%s)", s.Reason)
+	default:
+		return "stopped"
+	}
+}
+
+// getServerStatus 从状态文件, 进程信息和 Server List Ping 获取服务器的状态, 获取不到的部分保持为零值
+func getServerStatus(config configs.Server) serverStatus {
+	logger := log.WithField("name", config.Name)
+	serverDir := filepath.Join(configs.ServersDir, config.Name)
+	values, _ := properties.Load(filepath.Join(serverDir, "server.properties"))
+	status := serverStatus{Name: config.Name, Port: 25565}
+	if port, err := strconv.Atoi(values["server-port"]); err == nil {
+		status.Port = port
+	}
+	state, err := supervisor.LoadState(config.Name)
+	if err != nil {
+		return status
+	}
+	status.Parked, status.ExitCode, status.Reason = state.Parked, state.ExitCode, state.Reason
+	if !state.Alive() {
+		return status
+	}
+	status.Running = true
+	status.PID = state.PID
+	status.StartTime = state.StartTime
+	status.Uptime = time.Since(state.StartTime).Round(time.Second).String()
+	status.Reason = ""
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cpuPercent, rss, err := processTreeUsage(state.PID)
+		if err != nil {
+			logger.WithError(err).Debug("无法获取进程信息")
+		}
+		status.CPUPercent, status.RSS = cpuPercent, rss
+	}()
+	// 没有设置 server-ip 时服务器监听所有地址, 使用本机地址
+	host := values["server-ip"]
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ping, pingErr := slp.Ping(net.JoinHostPort(host, strconv.Itoa(status.Port)), pingTimeout)
+	wg.Wait()
+	if pingErr != nil {
+		logger.WithError(pingErr).Debug("Server List Ping 失败")
+		return status
+	}
+	status.Version = ping.Version.Name
+	status.PlayersOnline, status.PlayersMax = ping.Players.Online, ping.Players.Max
+	status.MOTD = string(ping.Description)
+	return status
+}
+
+// processTreeUsage 返回进程和它的所有子进程在 cpuSampleInterval 内的 CPU 使用率和常驻内存
+func processTreeUsage(pid int) (float64, uint64, error) {
+	root, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	tree := []*process.Process{root}
+	for i := 0; i < len(tree); i++ {
+		children, _ := tree[i].Children()
+		tree = append(tree, children...)
+	}
+
+	var cpuPercent float64
+	var rss uint64
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, p := range tree {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			percent, err := p.Percent(cpuSampleInterval)
+			memory, memErr := p.MemoryInfo()
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				cpuPercent += percent
+			}
+			if memErr == nil {
+				rss += memory.RSS
+			}
+		}()
+	}
+	wg.Wait()
+	return cpuPercent, rss, nil
+}
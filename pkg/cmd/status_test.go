@@ -0,0 +1,81 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/supervisor"
+	"github.com/Arama0517/MCST/pkg/cmd"
+)
+
+func TestStatus(t *testing.T) {
+	runDir, serversDir, servers := configs.RunDir, configs.ServersDir, configs.Configs.Servers
+	defer func() { configs.RunDir, configs.ServersDir, configs.Configs.Servers = runDir, serversDir, servers }()
+	configs.RunDir, configs.ServersDir = t.TempDir(), t.TempDir()
+	configs.Configs.Servers = map[string]configs.Server{}
+	for _, name := range []string{"running", "stopped"} {
+		if err := os.Mkdir(filepath.Join(configs.ServersDir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		configs.Configs.Servers[name] = configs.Server{
+			Name:       name,
+			ServerArgs: []string{"-c", "read line"},
+			Launch:     configs.Launch{Mode: configs.LaunchExecutable, Executable: "sh"},
+			Shutdown:   configs.Shutdown{Timeout: "1s"},
+		}
+	}
+	// 没有服务器监听这个端口, Server List Ping 会失败
+	properties := "server-port=1\n"
+	if err := os.WriteFile(filepath.Join(configs.ServersDir, "running", "server.properties"), []byte(properties), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	process, err := supervisor.Start(configs.Configs.Servers["running"], io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer process.Shutdown(supervisor.ReasonStop, 0)
+	cmd.ExitFunc = func(code int) {
+		t.Fatalf("退出代码: %d", code)
+	}
+
+	data := captureStdout(t, func() {
+		cmd.Execute([]string{"status", "--output", "json"})
+	})
+	var statuses []struct {
+		Name    string `json:"name"`
+		Running bool   `json:"running"`
+		PID     int    `json:"pid"`
+		Port    int    `json:"port"`
+	}
+	if err = json.Unmarshal(data, &statuses); err != nil {
+		t.Fatalf("无法解析输出: %v\n%s", err, data)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "running" || statuses[1].Running {
+		t.Fatalf("输出不正确: %s", data)
+	}
+	if !statuses[0].Running || statuses[0].PID != process.PID || statuses[0].Port != 1 {
+		t.Fatalf("输出不正确: %s", data)
+	}
+}
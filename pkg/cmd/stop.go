@@ -0,0 +1,82 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/supervisor"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+func newStopCmd() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:               "stop <name>",
+		Short:             locale.GetLocaleMessage("stop.short"),
+		Long:              locale.GetLocaleMessage("stop.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeServerNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, exists := configs.Configs.Servers[args[0]]
+			if !exists {
+				return MCSTErrors.ErrServerNotFound
+			}
+			if err := supervisor.Stop(cmd.Context(), config, timeout); err != nil {
+				return err
+			}
+			log.WithField("name", config.Name).Info("服务器已停止")
+			return nil
+		},
+	}
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, locale.GetLocaleMessage("stop.flags.timeout"))
+	return cmd
+}
+
+func newRestartCmd() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:               "restart <name>",
+		Short:             locale.GetLocaleMessage("restart.short"),
+		Long:              locale.GetLocaleMessage("restart.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeServerNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, exists := configs.Configs.Servers[args[0]]
+			if !exists {
+				return MCSTErrors.ErrServerNotFound
+			}
+			if err := supervisor.Restart(cmd.Context(), config, timeout); err != nil {
+				return err
+			}
+			log.WithField("name", config.Name).Infof("服务器已重启, 使用 'MCST attach %s' 连接控制台", config.Name)
+			return nil
+		},
+	}
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, locale.GetLocaleMessage("stop.flags.timeout"))
+	return cmd
+}
@@ -0,0 +1,132 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/store"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+func newGCCmd() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:               "gc",
+		Short:             locale.GetLocaleMessage("download.gc.short"),
+		Long:              locale.GetLocaleMessage("download.gc.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(*cobra.Command, []string) error {
+			referenced, err := referencedBlobs()
+			if err != nil {
+				return err
+			}
+			removed, err := store.GC(referenced, dryRun)
+			for _, blob := range removed {
+				log.WithField("dry_run", dryRun).Info(blob)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("共清理 %d 个文件", len(removed))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry_run", false, locale.GetLocaleMessage("download.gc.flags.dry_run"))
+	return cmd
+}
+
+// referencedBlobs 返回被核心或服务器使用的文件
+func referencedBlobs() (map[string]bool, error) {
+	referenced := map[string]bool{}
+	for _, core := range configs.Configs.Cores {
+		if core.SHA256 != "" {
+			referenced[core.SHA256] = true
+		}
+	}
+	for name, server := range configs.Configs.Servers {
+		for _, core := range []*configs.ServerCore{server.Core, server.Previous} {
+			if core != nil && core.SHA256 != "" {
+				referenced[core.SHA256] = true
+			}
+		}
+		checksums, err := download.FileChecksums(filepath.Join(configs.ServersDir, name, configs.ServerJar))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		referenced[checksums.SHA256] = true
+	}
+	return referenced, nil
+}
+
+func newVerifyCmd() *cobra.Command {
+	var remove bool
+	cmd := &cobra.Command{
+		Use:               "verify",
+		Short:             locale.GetLocaleMessage("download.verify.short"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(*cobra.Command, []string) error {
+			blobs, err := store.Blobs()
+			if err != nil {
+				return err
+			}
+			broken := 0
+			for _, blob := range blobs {
+				if err = store.Verify(blob); err == nil {
+					log.WithField("status", "ok").Debug(blob)
+					continue
+				}
+				broken++
+				log.WithError(err).Error(blob)
+				if remove {
+					path, err := store.Path(blob)
+					if err != nil {
+						return err
+					}
+					if err = os.Remove(path); err != nil {
+						return err
+					}
+				}
+			}
+			log.Infof("共检查 %d 个文件, %d 个已损坏", len(blobs), broken)
+			if broken != 0 {
+				return fmt.Errorf("%w: %d", MCSTErrors.ErrStoreCorrupted, broken)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&remove, "delete", false, locale.GetLocaleMessage("download.verify.flags.delete"))
+	return cmd
+}
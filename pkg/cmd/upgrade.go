@@ -0,0 +1,132 @@
+/*
+ * Minecraft Server Tool(MCST) is a command-line utility making Minecraft server creation quick and easy for beginners.
+ * Copyright (c) 2024-2024 Arama.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Arama0517/MCST/internal/configs"
+	"github.com/Arama0517/MCST/internal/download"
+	MCSTErrors "github.com/Arama0517/MCST/internal/errors"
+	"github.com/Arama0517/MCST/internal/locale"
+	"github.com/Arama0517/MCST/internal/store"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// backupSuffix 升级前的 server.jar 会被重命名为 server.jar.bak
+const backupSuffix = ".bak"
+
+type upgradeCmdFlags struct {
+	name     string
+	core     int
+	rollback bool
+}
+
+func newUpgradeCmd() *cobra.Command {
+	flags := upgradeCmdFlags{}
+	cmd := &cobra.Command{
+		Use:               "upgrade",
+		Short:             locale.GetLocaleMessage("upgrade.short"),
+		Long:              locale.GetLocaleMessage("upgrade.long"),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(*cobra.Command, []string) error {
+			config, exists := configs.Configs.Servers[flags.name]
+			if !exists {
+				return MCSTErrors.ErrServerNotFound
+			}
+			jarPath := filepath.Join(configs.ServersDir, config.Name, configs.ServerJar)
+			if flags.rollback {
+				return rollbackServer(config, jarPath)
+			}
+			core, exists := configs.Configs.Cores[flags.core]
+			if !exists {
+				return MCSTErrors.ErrCoreNotFound
+			}
+			if core.Installer != nil || config.Launch.Mode != configs.LaunchJar || config.Launch.Jar != configs.ServerJar {
+				return MCSTErrors.ErrInstallerUpgrade
+			}
+			return upgradeServer(config, core, jarPath)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.name, "name", "n", "", locale.GetLocaleMessage("upgrade.flags.name"))
+	cmd.Flags().IntVarP(&flags.core, "core", "c", 0, locale.GetLocaleMessage("create.flags.core"))
+	cmd.Flags().BoolVar(&flags.rollback, "rollback", false, locale.GetLocaleMessage("upgrade.flags.rollback"))
+	cmd.MarkFlagsMutuallyExclusive("core", "rollback")
+	cmd.MarkFlagsOneRequired("core", "rollback")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.RegisterFlagCompletionFunc("core", completeCoreIDs)
+	return cmd
+}
+
+// upgradeServer 备份当前的 server.jar 并替换为新的核心
+func upgradeServer(config configs.Server, core configs.Core, jarPath string) error {
+	if core.SHA1 != "" || core.SHA256 != "" {
+		checksums, err := download.FileChecksums(core.FilePath)
+		if err != nil {
+			return err
+		}
+		if err = checksums.Verify(core.SHA1, core.SHA256); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(jarPath, jarPath+backupSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := store.Link(core.FilePath, jarPath); err != nil {
+		// 恢复备份
+		_ = os.Rename(jarPath+backupSuffix, jarPath)
+		return err
+	}
+	config.Previous = config.Core
+	config.Core = configs.NewServerCore(core)
+	configs.Configs.Servers[config.Name] = config
+	log.WithField("backup", jarPath+backupSuffix).Info("升级成功, 可以使用 --rollback 回滚")
+	return configs.Configs.Save()
+}
+
+// rollbackServer 交换 server.jar 和备份, 再次回滚会回到升级后的核心
+func rollbackServer(config configs.Server, jarPath string) error {
+	backupPath := jarPath + backupSuffix
+	if _, err := os.Stat(backupPath); err != nil {
+		if os.IsNotExist(err) {
+			return MCSTErrors.ErrNoBackup
+		}
+		return err
+	}
+	tmpPath := jarPath + ".tmp"
+	if err := os.Rename(jarPath, tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(backupPath, jarPath); err != nil {
+		_ = os.Rename(tmpPath, jarPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, backupPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	config.Core, config.Previous = config.Previous, config.Core
+	configs.Configs.Servers[config.Name] = config
+	log.Info("回滚成功")
+	return configs.Configs.Save()
+}